@@ -19,6 +19,7 @@ package v1beta1
 import (
 	"github.com/openstack-k8s-operators/lib-common/modules/common/condition"
 	"github.com/openstack-k8s-operators/lib-common/modules/storage"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -35,6 +36,9 @@ const (
 	// DeploymentHash hash used to detect changes
 	DeploymentHash = "deployment"
 
+	// InputHashPrefix prefix of the hash of each input merged into the common.InputHashName one
+	InputHashPrefix = "input-"
+
 	// Container image fall-back defaults
 
 	// CinderAPIContainerImage is the fall-back container image for CinderAPI
@@ -46,6 +50,19 @@ const (
 	// CinderVolumeContainerImage is the fall-back container image for CinderVolume
 	CinderVolumeContainerImage = "quay.io/podified-antelope-centos9/openstack-cinder-volume:current-podified"
 
+	// PauseStatefulSetAnnotation - when set to "true" on a CinderAPI, CinderScheduler, CinderBackup
+	// or CinderVolume, the service config keeps being reconciled but its StatefulSet is not patched
+	PauseStatefulSetAnnotation = "cinder.openstack.org/pause-statefulset"
+	// DeferRolloutAnnotation - when set to "true" on a CinderAPI, CinderScheduler, CinderBackup
+	// or CinderVolume, changes to the pods of an existing StatefulSet are not rolled out
+	DeferRolloutAnnotation = "cinder.openstack.org/defer-rollout"
+	// MaintenanceWindowAnnotation - daily "HH:MM-HH:MM" UTC window out of which changes to the
+	// pods of an existing StatefulSet are not rolled out, e.g. "22:00-02:00"
+	MaintenanceWindowAnnotation = "cinder.openstack.org/maintenance-window"
+	// ImagePullSecretsAnnotation - set by the operator on its ServiceAccount to the comma separated
+	// names of the pull secrets it attached from ServiceAccountImagePullSecrets
+	ImagePullSecretsAnnotation = "cinder.openstack.org/image-pull-secrets"
+
 	// DBPurgeDefaultAge - Default age, in days, for purging deleted DB records
 	DBPurgeDefaultAge = 30
 	// DBPurgeDefaultSchedule - Default cron schedule for purging the DB
@@ -83,6 +100,14 @@ type CinderSpec struct {
 	// PreserveJobs - do not delete jobs after they finished e.g. to check logs
 	PreserveJobs bool `json:"preserveJobs"`
 
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=true
+	// PreserveDatabase - keep the cinder database when the Cinder CR gets deleted. The MariaDBDatabase
+	// and MariaDBAccount CRs, and the account Secret, are then orphaned and have to be deleted by hand
+	// once the database is no longer needed. When false the MariaDBDatabase is deleted, which drops
+	// the database before the Cinder CR goes away, unless another service shares it
+	PreserveDatabase *bool `json:"preserveDatabase,omitempty"`
+
 	// +kubebuilder:validation:Optional
 	// CustomServiceConfig - customize the service config for all Cinder services using this parameter to change service defaults,
 	// or overwrite rendered information using raw OpenStack config format. The content gets added to
@@ -118,6 +143,56 @@ type CinderSpec struct {
 	// +kubebuilder:validation:Optional
 	// DBPurge parameters -
 	DBPurge DBPurge `json:"dbPurge,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// HostSuffix - suffix appended to the cinder-volume services host value (e.g. the namespace name)
+	// to keep them unique across multiple Cinder deployments that may share a storage backend.
+	// Changing it on an existing deployment orphans the volumes owned by the previous host.
+	HostSuffix string `json:"hostSuffix,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// QuotaDriver - driver used by Cinder to enforce quotas, rendered as [DEFAULT] quota_driver
+	// (e.g. cinder.quota.DbQuotaDriver). The Cinder default applies when not set.
+	QuotaDriver string `json:"quotaDriver,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=3600
+	// RPCResponseTimeout - seconds to wait for a response to an RPC call between the Cinder services,
+	// rendered as [DEFAULT] rpc_response_timeout. The Cinder default applies when not set.
+	RPCResponseTimeout *int32 `json:"rpcResponseTimeout,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// DefaultVolumeType - volume type used when none is requested on volume creation,
+	// rendered as [DEFAULT] default_volume_type. The Cinder default applies when not set.
+	DefaultVolumeType string `json:"defaultVolumeType,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// AllowAvailabilityZoneFallback - when the requested availability zone is not available,
+	// create the volume in the default availability zone instead of failing the request.
+	// Rendered as [DEFAULT] allow_availability_zone_fallback, the Cinder default applies when not set.
+	AllowAvailabilityZoneFallback *bool `json:"allowAvailabilityZoneFallback,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// GlanceAPIInsecure - skip the verification of the Glance API certificate,
+	// rendered as [DEFAULT] glance_api_insecure
+	GlanceAPIInsecure bool `json:"glanceAPIInsecure,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// KeystoneAuthtokenCache - keystonemiddleware token cache parameters
+	KeystoneAuthtokenCache KeystoneAuthtokenCache `json:"keystoneAuthtokenCache,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// ServiceAccountImagePullSecrets - pull secrets attached to the ServiceAccount managed by the
+	// operator, so all the Cinder pods inherit them. Secrets already present on the ServiceAccount
+	// are preserved, and secrets removed from this list are detached.
+	ServiceAccountImagePullSecrets []corev1.LocalObjectReference `json:"serviceAccountImagePullSecrets,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// OrderedDeployment - create the CinderVolumes only once CinderAPI and CinderScheduler are Ready,
+	// and the CinderBackup only once the CinderVolumes are Ready, instead of creating all of them at once.
+	// Services which already exist are always updated.
+	OrderedDeployment bool `json:"orderedDeployment,omitempty"`
 }
 
 // CinderStatus defines the observed state of Cinder
@@ -151,6 +226,14 @@ type CinderStatus struct {
 
 	// ReadyCounts of Cinder Volume instances
 	CinderVolumesReadyCounts map[string]int32 `json:"cinderVolumesReadyCounts,omitempty"`
+
+	// DeploymentWaitingOn - services whose creation is held by OrderedDeployment,
+	// mapped to the services they are waiting for to be Ready
+	DeploymentWaitingOn map[string][]string `json:"deploymentWaitingOn,omitempty"`
+
+	// RequeueReason - why the last reconcile did not complete and is retried, e.g. a missing Secret
+	// or a dependency which is not ready yet. Empty once the reconcile completes.
+	RequeueReason string `json:"requeueReason,omitempty"`
 }
 
 //+kubebuilder:object:root=true
@@ -180,6 +263,25 @@ type DBPurge struct {
 	Schedule string `json:"schedule"`
 }
 
+// KeystoneAuthtokenCache defines how keystonemiddleware caches the validated tokens in memcached
+type KeystoneAuthtokenCache struct {
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=0
+	// TokenCacheTime - seconds a validated token is cached, rendered as [keystone_authtoken] token_cache_time
+	TokenCacheTime *int32 `json:"tokenCacheTime,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=None;MAC;ENCRYPT
+	// MemcacheSecurityStrategy - authenticate (MAC) or authenticate and encrypt (ENCRYPT) the cached tokens,
+	// rendered as [keystone_authtoken] memcache_security_strategy
+	MemcacheSecurityStrategy string `json:"memcacheSecurityStrategy,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// MemcacheSecretKeySelector - key in the Secret holding the memcache_secret_key, required by the
+	// MAC and ENCRYPT security strategies
+	MemcacheSecretKeySelector string `json:"memcacheSecretKeySelector,omitempty"`
+}
+
 // CinderDebug contains flags related to multiple debug activities. See the
 // individual comments for what this means for each flag.
 type CinderDebug struct {
@@ -22,8 +22,15 @@ limitations under the License.
 package v1beta1
 
 import (
+	"sort"
+
 	"github.com/openstack-k8s-operators/lib-common/modules/common/util"
+	"github.com/openstack-k8s-operators/lib-common/modules/storage"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	ctrl "sigs.k8s.io/controller-runtime"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
@@ -116,7 +123,14 @@ var _ webhook.Validator = &Cinder{}
 func (r *Cinder) ValidateCreate() (admission.Warnings, error) {
 	cinderlog.Info("validate create", "name", r.Name)
 
-	// TODO(user): fill in your validation logic upon object creation.
+	allErrs := r.Spec.ValidateExtraMounts(field.NewPath("spec").Child("extraMounts"))
+	allErrs = append(allErrs, r.Spec.CinderAPI.ValidateExtraContainers(
+		field.NewPath("spec").Child("cinderAPI"), r.Name+"-api")...)
+	allErrs = append(allErrs, r.Spec.ValidateNodeSelectors(field.NewPath("spec"))...)
+	if len(allErrs) != 0 {
+		return nil, apierrors.NewInvalid(GroupVersion.WithKind("Cinder").GroupKind(), r.Name, allErrs)
+	}
+
 	return nil, nil
 }
 
@@ -124,7 +138,14 @@ func (r *Cinder) ValidateCreate() (admission.Warnings, error) {
 func (r *Cinder) ValidateUpdate(old runtime.Object) (admission.Warnings, error) {
 	cinderlog.Info("validate update", "name", r.Name)
 
-	// TODO(user): fill in your validation logic upon object update.
+	allErrs := r.Spec.ValidateExtraMounts(field.NewPath("spec").Child("extraMounts"))
+	allErrs = append(allErrs, r.Spec.CinderAPI.ValidateExtraContainers(
+		field.NewPath("spec").Child("cinderAPI"), r.Name+"-api")...)
+	allErrs = append(allErrs, r.Spec.ValidateNodeSelectors(field.NewPath("spec"))...)
+	if len(allErrs) != 0 {
+		return nil, apierrors.NewInvalid(GroupVersion.WithKind("Cinder").GroupKind(), r.Name, allErrs)
+	}
+
 	return nil, nil
 }
 
@@ -135,3 +156,72 @@ func (r *Cinder) ValidateDelete() (admission.Warnings, error) {
 	// TODO(user): fill in your validation logic upon object deletion.
 	return nil, nil
 }
+
+// ValidateNodeSelectors - the node selectors of the Cinder and of its services
+// must be valid labels, an invalid one would only be rejected once the
+// StatefulSets get created
+func (spec *CinderSpec) ValidateNodeSelectors(basePath *field.Path) field.ErrorList {
+	allErrs := metav1validation.ValidateLabels(spec.NodeSelector, basePath.Child("nodeSelector"))
+	allErrs = append(allErrs, metav1validation.ValidateLabels(
+		spec.CinderAPI.NodeSelector, basePath.Child("cinderAPI", "nodeSelector"))...)
+	allErrs = append(allErrs, metav1validation.ValidateLabels(
+		spec.CinderScheduler.NodeSelector, basePath.Child("cinderScheduler", "nodeSelector"))...)
+	allErrs = append(allErrs, metav1validation.ValidateLabels(
+		spec.CinderBackup.NodeSelector, basePath.Child("cinderBackup", "nodeSelector"))...)
+
+	names := make([]string, 0, len(spec.CinderVolumes))
+	for name := range spec.CinderVolumes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		allErrs = append(allErrs, metav1validation.ValidateLabels(
+			spec.CinderVolumes[name].NodeSelector, basePath.Child("cinderVolumes").Key(name).Child("nodeSelector"))...)
+	}
+
+	return allErrs
+}
+
+// ValidateExtraMounts - Bidirectional mount propagation is only allowed in
+// privileged containers, so the ExtraMounts using it must be propagated only
+// to the cinder-volume and cinder-backup pods
+func (spec *CinderSpec) ValidateExtraMounts(basePath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	for i, extraMount := range spec.ExtraMounts {
+		for j, volMount := range extraMount.VolMounts {
+			if spec.propagatedToPrivilegedOnly(volMount.Propagation) {
+				continue
+			}
+			for k, mount := range volMount.Mounts {
+				if mount.MountPropagation != nil && *mount.MountPropagation == corev1.MountPropagationBidirectional {
+					allErrs = append(allErrs, field.Invalid(
+						basePath.Index(i).Child("extraVol").Index(j).Child("mounts").Index(k).Child("mountPropagation"),
+						*mount.MountPropagation,
+						"Bidirectional mount propagation requires the extraVol propagation to be limited to CinderVolume, CinderBackup or cinderVolumes backend names"))
+				}
+			}
+		}
+	}
+
+	return allErrs
+}
+
+// propagatedToPrivilegedOnly - returns true if the propagation list only
+// targets the privileged CinderVolume and CinderBackup services
+func (spec *CinderSpec) propagatedToPrivilegedOnly(propagation []storage.PropagationType) bool {
+	// no propagation means the volume is mounted everywhere
+	if len(propagation) == 0 {
+		return false
+	}
+	for _, p := range propagation {
+		if p == "CinderVolume" || p == "CinderBackup" {
+			continue
+		}
+		if _, ok := spec.CinderVolumes[string(p)]; ok {
+			continue
+		}
+		return false
+	}
+	return true
+}
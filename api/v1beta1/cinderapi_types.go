@@ -20,7 +20,24 @@ import (
 	condition "github.com/openstack-k8s-operators/lib-common/modules/common/condition"
 	"github.com/openstack-k8s-operators/lib-common/modules/common/service"
 	"github.com/openstack-k8s-operators/lib-common/modules/common/tls"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+const (
+	// ComponentAntiAffinitySoft - the cinder-api pods avoid the nodes of the other components when possible
+	ComponentAntiAffinitySoft = "soft"
+	// ComponentAntiAffinityHard - the cinder-api pods are never scheduled on the nodes of the other components
+	ComponentAntiAffinityHard = "hard"
+
+	// CinderAPIContainerName - name of the cinder-api container of the pods
+	CinderAPIContainerName = "cinder-api"
+	// CinderAPILogContainerSuffix - appended to the name of the CinderAPI to name the log container of the pods
+	CinderAPILogContainerSuffix = "-log"
+	// CinderAPIDBWaitContainerName - name of the init container of the pods waiting for the database
+	CinderAPIDBWaitContainerName = "wait-for-db"
 )
 
 // CinderAPITemplate defines the input parameters for the Cinder API service
@@ -42,6 +59,338 @@ type CinderAPITemplate struct {
 	// +operator-sdk:csv:customresourcedefinitions:type=spec
 	// TLS - Parameters related to the TLS
 	TLS tls.API `json:"tls,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// ExtraCaBundleSecretNames - additional Secrets holding CA bundles, e.g. of internal CAs of the storage
+	// backends, mounted next to the one of the TLS CaBundleSecretName. Each Secret is mounted as a
+	// directory named after it in /etc/pki/cinder/ca-bundles.
+	ExtraCaBundleSecretNames []string `json:"extraCaBundleSecretNames,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=public
+	// +kubebuilder:validation:Enum=public;internal
+	// ProbeEndpoint - endpoint (public or internal) targeted by the liveness and readiness probes.
+	// The probe scheme follows the TLS configuration of the selected endpoint.
+	ProbeEndpoint service.Endpoint `json:"probeEndpoint,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=false
+	// CanaryRollout - roll out configuration and image changes to a single replica first and only
+	// update the remaining replicas once the canary replica is Ready
+	CanaryRollout bool `json:"canaryRollout"`
+
+	// +kubebuilder:validation:Optional
+	// UpdateStrategy - update strategy of the cinder-api StatefulSet, e.g. a RollingUpdate partition or
+	// maxUnavailable. The partition is managed by the operator when CanaryRollout is enabled.
+	UpdateStrategy *appsv1.StatefulSetUpdateStrategy `json:"updateStrategy,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=600
+	// +kubebuilder:validation:Minimum=1
+	// ProgressDeadlineSeconds - seconds a rollout of the StatefulSet can take before it is reported
+	// by the RolloutStuck condition
+	ProgressDeadlineSeconds int32 `json:"progressDeadlineSeconds"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=true
+	// KeystoneServiceEnabled - register the Cinder services as enabled in the Keystone catalog.
+	// Can be set to false to pre-register the services before a catalog cutover.
+	KeystoneServiceEnabled *bool `json:"keystoneServiceEnabled,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// AdoptExistingKeystoneService - do not create the KeystoneService and KeystoneEndpoint CRs, but
+	// take the service IDs from the KeystoneService CRs of the same name registered out-of-band. The
+	// adopted services and endpoints are neither patched nor deleted by the operator.
+	AdoptExistingKeystoneService bool `json:"adoptExistingKeystoneService,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// DryRun - do not create or patch any of the cinder-api resources, but report the StatefulSet,
+	// Services and Keystone registrations the operator would apply in the DryRun condition, e.g. to
+	// review the planned changes in a change controlled environment
+	DryRun bool `json:"dryRun,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// Paused - halt the reconciliation of the cinder-api, none of its resources are touched until it
+	// is resumed, e.g. to keep the operator from reverting manual changes during a maintenance
+	Paused bool `json:"paused,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=true
+	// ExposePublic - create the public Service, from which the public Route gets created, and register the
+	// public endpoint in Keystone. Can be set to false to keep cinder-api internal only, e.g. behind a gateway.
+	ExposePublic *bool `json:"exposePublic,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// KeystoneServiceDescriptions - map of Keystone service name (e.g. cinderv3) to the description
+	// registered in the Keystone catalog. Services not listed use the default description.
+	KeystoneServiceDescriptions map[string]string `json:"keystoneServiceDescriptions,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// KeystoneServiceDescriptionSuffix - appended to the default description of the Keystone services,
+	// separated by " - ", e.g. to tell apart the services of the sites of a multi-site deployment. Not
+	// applied to the KeystoneServiceDescriptions overrides.
+	KeystoneServiceDescriptionSuffix string `json:"keystoneServiceDescriptionSuffix,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:MaxLength=54
+	// +kubebuilder:validation:Pattern=`^[a-z]([-a-z0-9]*[a-z0-9])?$`
+	// ServiceName - base name of the Service created for each endpoint type (public, internal), which
+	// is named <serviceName>-<endpoint type>, e.g. to run several cinder-api in a namespace. Defaults
+	// to cinder. The metrics Service is named <serviceName>-metrics, or after the CinderAPI when unset.
+	ServiceName string `json:"serviceName,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// PortNames - name of the port of the Service created for each endpoint type (public, internal),
+	// e.g. to match the conventions of the monitoring or ingress configuration. Endpoint types not
+	// listed keep the name of the Service.
+	PortNames map[service.Endpoint]string `json:"portNames,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// APIPorts - port the cinder-api listens on and its Service exposes for each endpoint type (public,
+	// internal), e.g. to avoid a port conflict behind a proxy. Endpoint types not listed use 8776.
+	APIPorts map[service.Endpoint]int32 `json:"apiPorts,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=soft;hard
+	// ComponentAntiAffinity - keep the cinder-api pods away from the nodes running cinder-scheduler and
+	// cinder-volume pods, when possible (soft) or strictly (hard). Disabled when not set.
+	ComponentAntiAffinity string `json:"componentAntiAffinity,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=3
+	// +kubebuilder:validation:Minimum=1
+	// LivenessFailureThreshold - consecutive failures of the liveness probe before the cinder-api container
+	// is restarted. Set it higher than ReadinessFailureThreshold so that a transient slowness only takes
+	// the pod out of the Service endpoints instead of restarting it.
+	LivenessFailureThreshold int32 `json:"livenessFailureThreshold"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=3
+	// +kubebuilder:validation:Minimum=1
+	// ReadinessFailureThreshold - consecutive failures of the readiness probe before the pod is taken out
+	// of the Service endpoints
+	ReadinessFailureThreshold int32 `json:"readinessFailureThreshold"`
+
+	// +kubebuilder:validation:Optional
+	// WorkersFromCPULimit - run as many cinder-api worker processes as the CPU limit of the container,
+	// rounded up, instead of the fixed default of 4. Has no effect when no CPU limit is set.
+	WorkersFromCPULimit bool `json:"workersFromCPULimit,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	// APIWorkers - number of cinder-api worker processes, rendered as osapi_volume_workers. Takes
+	// precedence over WorkersFromCPULimit.
+	APIWorkers *int32 `json:"apiWorkers,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// ProbeConfig - timings of the startup, liveness and readiness probes of the cinder-api container,
+	// e.g. to give more time to pods behind a busy Keystone. Unset fields keep their default.
+	ProbeConfig ProbeConfig `json:"probeConfig,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default="/healthcheck"
+	// +kubebuilder:validation:Pattern=`^/`
+	// HealthCheckPath - HTTP path of the healthcheck targeted by the liveness and readiness probes,
+	// e.g. when cinder-api is served behind a path prefix
+	HealthCheckPath string `json:"healthCheckPath,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// ContainerCommand - command of the cinder-api container, e.g. to run a custom entrypoint, used
+	// instead of running kolla_start with bash. The debug mode has no effect on the command when set.
+	ContainerCommand []string `json:"containerCommand,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// ContainerArgs - arguments of the cinder-api container. Replaces the default arguments whenever
+	// ContainerCommand or ContainerArgs is set.
+	ContainerArgs []string `json:"containerArgs,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// LogImage - image of the container streaming the cinder-api log, which needs to provide dumb-init
+	// and tail. Defaults to the ContainerImage.
+	LogImage string `json:"logImage,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// LogResources - compute resources of the container streaming the cinder-api log. Defaults to
+	// small requests and limits sized for tail, not to the Resources of the API container.
+	LogResources *corev1.ResourceRequirements `json:"logResources,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Pattern=`^/.+/[^/]+$`
+	// LogFilePath - absolute path of the cinder-api log file streamed by the log container. Its
+	// directory is shared between the containers. Defaults to /var/log/cinder/cinder-api.log.
+	LogFilePath string `json:"logFilePath,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=true
+	// WaitForDatabase - start the cinder-api pods with an init container waiting until the database
+	// of the connection in the config accepts connections
+	WaitForDatabase *bool `json:"waitForDatabase,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// SecurityContext - user, group and filesystem group the cinder-api pods run with, e.g. to comply
+	// with a restricted PodSecurity policy. The containers run as root when not set.
+	SecurityContext APISecurityContext `json:"securityContext,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	// TerminationGracePeriodSeconds - seconds given to the cinder-api pods to complete the in-flight
+	// requests on termination. When set, a preStop hook first sleeps for a few seconds of that period so
+	// the endpoints drop the pod before httpd stops. Defaults to 30 seconds without preStop hook.
+	TerminationGracePeriodSeconds *int64 `json:"terminationGracePeriodSeconds,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:XIntOrString
+	// MaxUnavailable - number or percentage of the cinder-api pods which can be unavailable during
+	// voluntary disruptions, e.g. node drains, enforced by a PodDisruptionBudget. Defaults to 1. No
+	// PodDisruptionBudget is created for a single replica, as it would block the drains.
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// TopologySpreadConstraints - spread the cinder-api pods across topology domains, e.g. zones, in
+	// addition to the default pod anti-affinity. Constraints without a labelSelector select the
+	// cinder-api pods.
+	TopologySpreadConstraints []corev1.TopologySpreadConstraint `json:"topologySpreadConstraints,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// Tolerations - taints the cinder-api pods tolerate, e.g. to run them on tainted storage nodes. The
+	// NodeSelector still restricts the nodes the pods are scheduled on.
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// Autoscaling - scale the cinder-api pods on their CPU utilization with a HorizontalPodAutoscaler.
+	// Replicas is ignored when set, the number of replicas being managed by the HorizontalPodAutoscaler.
+	Autoscaling *APIAutoscaling `json:"autoscaling,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// EnableServiceMonitor - create a Prometheus Operator ServiceMonitor scraping the cinder-api metrics.
+	// Skipped, and reported by the CinderAPIServiceMonitorReady condition, when the ServiceMonitor CRD
+	// is not installed.
+	EnableServiceMonitor bool `json:"enableServiceMonitor,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=9102
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	// MetricsPort - port of the cinder-api metrics, exposed by a dedicated ClusterIP service
+	MetricsPort int32 `json:"metricsPort,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=10
+	// +kubebuilder:validation:Minimum=1
+	// KeystoneTimeout - seconds to wait before checking again on the keystone services and endpoints
+	// which are not registered yet
+	KeystoneTimeout int `json:"keystoneTimeout,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// ExtraEnv - extra environment variables of the cinder-api container, e.g. proxy settings. They
+	// can't override the variables set by the operator, like CONFIG_HASH.
+	ExtraEnv map[string]string `json:"extraEnv,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// PodLabels - extra labels of the cinder-api pods, e.g. to select them in network policies. They
+	// can't override the labels set by the operator, which select the pods of the StatefulSet.
+	PodLabels map[string]string `json:"podLabels,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// PodAnnotations - extra annotations of the cinder-api pods, e.g. to request a sidecar injection.
+	// They can't override the annotations set by the operator.
+	PodAnnotations map[string]string `json:"podAnnotations,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// ImagePullSecrets - pull secrets of the cinder-api pods, e.g. to pull the ContainerImage from a
+	// private registry
+	ImagePullSecrets []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// PriorityClassName - priority class of the cinder-api pods, to protect them from being evicted
+	// before less critical workloads under node pressure
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// ExtraContainers - sidecar containers added to the cinder-api pods, e.g. a metrics exporter or an
+	// audit sidecar. Their names must not collide with the containers managed by the operator.
+	ExtraContainers []corev1.Container `json:"extraContainers,omitempty"`
+}
+
+// APIAutoscaling defines the HorizontalPodAutoscaler of the cinder-api pods
+type APIAutoscaling struct {
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=1
+	// +kubebuilder:validation:Minimum=1
+	// MinReplicas - lower limit of the number of replicas
+	MinReplicas *int32 `json:"minReplicas,omitempty"`
+
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Minimum=1
+	// MaxReplicas - upper limit of the number of replicas
+	MaxReplicas int32 `json:"maxReplicas"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=80
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	// TargetCPUUtilization - average CPU utilization of the pods targeted, in percent of their CPU requests
+	TargetCPUUtilization *int32 `json:"targetCPUUtilization,omitempty"`
+}
+
+// APISecurityContext defines the user and groups the cinder-api pods run with
+type APISecurityContext struct {
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=0
+	// RunAsUser - UID the containers run as, defaults to 0
+	RunAsUser *int64 `json:"runAsUser,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=0
+	// RunAsGroup - GID the containers run as, defaults to the group of the image
+	RunAsGroup *int64 `json:"runAsGroup,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=0
+	// FSGroup - supplemental group owning the volumes of the pod
+	FSGroup *int64 `json:"fsGroup,omitempty"`
+}
+
+// ProbeConfig defines the timings of the cinder-api startup, liveness and readiness probes
+type ProbeConfig struct {
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	// LivenessTimeout - seconds after which the liveness probe times out, defaults to 5
+	LivenessTimeout *int32 `json:"livenessTimeout,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	// ReadinessTimeout - seconds after which the readiness probe times out, defaults to 5
+	ReadinessTimeout *int32 `json:"readinessTimeout,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	// PeriodSeconds - how often both probes are performed, defaults to 3 seconds for the liveness
+	// probe and 5 seconds for the readiness probe
+	PeriodSeconds *int32 `json:"periodSeconds,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=0
+	// InitialDelaySeconds - seconds after the container started before both probes are initiated,
+	// defaults to 5
+	InitialDelaySeconds *int32 `json:"initialDelaySeconds,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	// FailureThreshold - consecutive failures of both probes before they are considered failed,
+	// overrides LivenessFailureThreshold and ReadinessFailureThreshold when set
+	FailureThreshold *int32 `json:"failureThreshold,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	// StartupFailureThreshold - consecutive failures of the startup probe before the cinder-api container
+	// is restarted, defaults to 12. The liveness and readiness probes only start once it succeeded.
+	StartupFailureThreshold *int32 `json:"startupFailureThreshold,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	// StartupPeriodSeconds - how often the startup probe is performed, defaults to 5 seconds
+	StartupPeriodSeconds *int32 `json:"startupPeriodSeconds,omitempty"`
 }
 
 // APIOverrideSpec to override the generated manifest of several child resources.
@@ -95,6 +444,15 @@ type CinderAPIStatus struct {
 
 	// NetworkAttachments status of the deployment pods
 	NetworkAttachments map[string][]string `json:"networkAttachments,omitempty"`
+
+	// RolloutStartTime - when the in progress rollout of the StatefulSet started
+	RolloutStartTime *metav1.Time `json:"rolloutStartTime,omitempty"`
+
+	// Summary - short human readable state of the service, e.g. "3/3 ready"
+	Summary string `json:"summary,omitempty"`
+
+	// MetricsEndpoint - URL of the cinder-api metrics
+	MetricsEndpoint string `json:"metricsEndpoint,omitempty"`
 }
 
 //+kubebuilder:object:root=true
@@ -102,6 +460,7 @@ type CinderAPIStatus struct {
 //+kubebuilder:printcolumn:name="NetworkAttachments",type="string",JSONPath=".status.networkAttachments",description="NetworkAttachments"
 //+kubebuilder:printcolumn:name="Status",type="string",JSONPath=".status.conditions[0].status",description="Status"
 //+kubebuilder:printcolumn:name="Message",type="string",JSONPath=".status.conditions[0].message",description="Message"
+//+kubebuilder:printcolumn:name="Summary",type="string",JSONPath=".status.summary",description="Summary"
 
 // CinderAPI is the Schema for the cinderapis API
 type CinderAPI struct {
@@ -127,5 +486,25 @@ func init() {
 
 // IsReady - returns true if service is ready to serve requests
 func (instance CinderAPI) IsReady() bool {
+	// the endpoints are missing from the catalog until the keystone
+	// services are registered
+	if !instance.Status.Conditions.IsTrue(condition.KeystoneServiceReadyCondition) {
+		return false
+	}
+	if instance.Spec.Autoscaling != nil {
+		return instance.Status.ReadyCount >= instance.ExpectedReplicas()
+	}
 	return instance.Status.ReadyCount == *instance.Spec.Replicas
 }
+
+// ExpectedReplicas - returns the number of replicas the service is expected to
+// run, the lower limit of the autoscaling when enabled
+func (instance CinderAPI) ExpectedReplicas() int32 {
+	if instance.Spec.Autoscaling != nil {
+		if instance.Spec.Autoscaling.MinReplicas != nil {
+			return *instance.Spec.Autoscaling.MinReplicas
+		}
+		return 1
+	}
+	return *instance.Spec.Replicas
+}
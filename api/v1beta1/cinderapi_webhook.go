@@ -0,0 +1,230 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/openstack-k8s-operators/lib-common/modules/common/service"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// log is for logging in this package.
+var cinderapilog = logf.Log.WithName("cinderapi-resource")
+
+// webhookReader - uncached reader of the manager, used to look up the secrets
+// referenced by a spec at admission time
+var webhookReader client.Reader
+
+// SetupWebhookWithManager sets up the webhook with the Manager
+func (r *CinderAPI) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	webhookReader = mgr.GetAPIReader()
+
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/mutate-cinder-openstack-org-v1beta1-cinderapi,mutating=true,failurePolicy=fail,sideEffects=None,groups=cinder.openstack.org,resources=cinderapis,verbs=create;update,versions=v1beta1,name=mcinderapi.kb.io,admissionReviewVersions=v1
+
+var _ webhook.Defaulter = &CinderAPI{}
+
+// Default implements webhook.Defaulter so a webhook will be registered for the type
+func (r *CinderAPI) Default() {
+	cinderapilog.Info("default", "name", r.Name)
+
+	if r.Spec.ContainerImage == "" {
+		r.Spec.ContainerImage = cinderDefaults.APIContainerImageURL
+	}
+
+	if r.Spec.Replicas == nil {
+		replicas := int32(1)
+		r.Spec.Replicas = &replicas
+	}
+
+	if r.Spec.ServiceAccount == "" {
+		r.Spec.ServiceAccount = r.owningCinder().RbacResourceName()
+	}
+}
+
+// owningCinder - returns the Cinder owning the CinderAPI, whose service account
+// is the one created for the cinder services. A standalone CinderAPI falls back
+// to the one of the conventional "cinder" Cinder.
+func (r *CinderAPI) owningCinder() Cinder {
+	owner := Cinder{}
+	owner.Name = "cinder"
+	for _, ownerRef := range r.GetOwnerReferences() {
+		if ownerRef.Kind == "Cinder" {
+			owner.Name = ownerRef.Name
+		}
+	}
+
+	return owner
+}
+
+//+kubebuilder:webhook:path=/validate-cinder-openstack-org-v1beta1-cinderapi,mutating=false,failurePolicy=fail,sideEffects=None,groups=cinder.openstack.org,resources=cinderapis,verbs=create;update,versions=v1beta1,name=vcinderapi.kb.io,admissionReviewVersions=v1
+
+var _ webhook.Validator = &CinderAPI{}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+func (r *CinderAPI) ValidateCreate() (admission.Warnings, error) {
+	cinderapilog.Info("validate create", "name", r.Name)
+
+	return r.validate()
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
+func (r *CinderAPI) ValidateUpdate(old runtime.Object) (admission.Warnings, error) {
+	cinderapilog.Info("validate update", "name", r.Name)
+
+	return r.validate()
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (r *CinderAPI) ValidateDelete() (admission.Warnings, error) {
+	cinderapilog.Info("validate delete", "name", r.Name)
+
+	return nil, nil
+}
+
+func (r *CinderAPI) validate() (admission.Warnings, error) {
+	allErrs := r.Spec.CinderAPITemplate.Validate(field.NewPath("spec"))
+	allErrs = append(allErrs, r.Spec.CinderAPITemplate.ValidateExtraContainers(field.NewPath("spec"), r.Name)...)
+	if len(allErrs) != 0 {
+		return nil, apierrors.NewInvalid(GroupVersion.WithKind("CinderAPI").GroupKind(), r.Name, allErrs)
+	}
+
+	warnings := r.caBundleWarnings()
+	warnings = append(warnings, r.certSecretWarnings()...)
+	return warnings, nil
+}
+
+// caBundleWarnings - the CA bundle secret can legitimately be created after
+// the CinderAPI, so a missing one only results in a warning
+func (r *CinderAPI) caBundleWarnings() admission.Warnings {
+	caBundleSecretName := r.Spec.TLS.CaBundleSecretName
+	if caBundleSecretName == "" {
+		return nil
+	}
+
+	return r.missingSecretWarnings("spec.tls.caBundleSecretName", caBundleSecretName)
+}
+
+// certSecretWarnings - same as for the CA bundle, the cert secrets of the TLS
+// endpoints are usually issued along with the CinderAPI, so a missing one only
+// results in a warning
+func (r *CinderAPI) certSecretWarnings() admission.Warnings {
+	var warnings admission.Warnings
+	for _, endpt := range []service.Endpoint{service.EndpointPublic, service.EndpointInternal} {
+		if !r.Spec.TLS.API.Enabled(endpt) {
+			continue
+		}
+		secretName := r.Spec.TLS.API.Public.SecretName
+		if endpt == service.EndpointInternal {
+			secretName = r.Spec.TLS.API.Internal.SecretName
+		}
+		warnings = append(warnings, r.missingSecretWarnings(
+			fmt.Sprintf("spec.tls.api.%s.secretName", endpt.String()), *secretName)...)
+	}
+
+	return warnings
+}
+
+// missingSecretWarnings - returns a warning for the field at path when the
+// secret it references doesn't exist in the namespace of the CinderAPI
+func (r *CinderAPI) missingSecretWarnings(path string, secretName string) admission.Warnings {
+	if webhookReader == nil {
+		return nil
+	}
+
+	err := webhookReader.Get(
+		context.TODO(),
+		types.NamespacedName{Name: secretName, Namespace: r.Namespace},
+		&corev1.Secret{})
+	if apierrors.IsNotFound(err) {
+		return admission.Warnings{
+			fmt.Sprintf("%s: secret %s not found, the CinderAPI pods won't start until it exists", path, secretName),
+		}
+	} else if err != nil {
+		cinderapilog.Info("unable to check the secret", "name", r.Name, "secret", secretName, "error", err.Error())
+	}
+
+	return nil
+}
+
+// Validate - rejects the CinderAPI specs which would result in a broken
+// StatefulSet
+func (spec *CinderAPITemplate) Validate(basePath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if spec.Replicas != nil && *spec.Replicas < 0 {
+		allErrs = append(allErrs, field.Invalid(
+			basePath.Child("replicas"), *spec.Replicas, "must be greater than or equal to 0"))
+	}
+
+	allErrs = append(allErrs, metav1validation.ValidateLabels(spec.NodeSelector, basePath.Child("nodeSelector"))...)
+
+	// An empty secretName disables TLS on the endpoint, which is most likely
+	// a template missing the cert secret rather than a request for plain http
+	for _, endpt := range []service.Endpoint{service.EndpointPublic, service.EndpointInternal} {
+		secretName := spec.TLS.API.Public.SecretName
+		if endpt == service.EndpointInternal {
+			secretName = spec.TLS.API.Internal.SecretName
+		}
+		if secretName != nil && *secretName == "" {
+			allErrs = append(allErrs, field.Required(
+				basePath.Child("tls", "api", endpt.String(), "secretName"),
+				"the cert secret of a TLS endpoint must not be empty, omit the secretName to disable TLS"))
+		}
+	}
+
+	return allErrs
+}
+
+// ValidateExtraContainers - rejects the extra containers named like another
+// one, or like a container managed by the operator in the pods of the
+// CinderAPI apiName
+func (spec *CinderAPITemplate) ValidateExtraContainers(basePath *field.Path, apiName string) field.ErrorList {
+	var allErrs field.ErrorList
+
+	// the log, cinder-api and wait-for-db init containers of the pods
+	names := map[string]bool{
+		apiName + CinderAPILogContainerSuffix: true,
+		CinderAPIContainerName:                true,
+		CinderAPIDBWaitContainerName:          true,
+	}
+	for i, container := range spec.ExtraContainers {
+		if names[container.Name] {
+			allErrs = append(allErrs, field.Duplicate(
+				basePath.Child("extraContainers").Index(i).Child("name"), container.Name))
+		}
+		names[container.Name] = true
+	}
+
+	return allErrs
+}
@@ -32,6 +32,12 @@ type CinderBackupTemplate struct {
 	// +kubebuilder:validation:Minimum=0
 	// Replicas - Cinder Backup Replicas
 	Replicas *int32 `json:"replicas"`
+
+	// +kubebuilder:validation:Optional
+	// PosixBackupPVC - name of an existing PersistentVolumeClaim used as the backup target. When set the
+	// claim is mounted in the cinder-backup pods and the posix backup driver stores the backups on it.
+	// The claim must support the ReadWriteMany access mode when running more than one replica.
+	PosixBackupPVC string `json:"posixBackupPVC,omitempty"`
 }
 
 // CinderBackupSpec defines the desired state of CinderBackup
@@ -33,6 +33,40 @@ type CinderVolumeTemplate struct {
 	// +kubebuilder:validation:Maximum=1
 	// Replicas - Cinder Volume Replicas
 	Replicas *int32 `json:"replicas"`
+
+	// +kubebuilder:validation:Optional
+	// BackendPools - map of backend names (the config section of the backend in CustomServiceConfig)
+	// to the pool the backend reports to the scheduler, rendered as pool_name in the backend section
+	BackendPools map[string]string `json:"backendPools,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// ReplicationDevices - map of backend names to the list of replication targets of the backend.
+	// Each target is a map of connection parameters (e.g. backend_id, san_ip) rendered as a
+	// replication_device line in the backend section
+	ReplicationDevices map[string][]map[string]string `json:"replicationDevices,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=1000
+	// ExecutorThreadPoolSize - number of RPC requests, e.g. volume creations, the service processes
+	// concurrently, rendered as [DEFAULT] executor_thread_pool_size. The Cinder default applies when not set.
+	ExecutorThreadPoolSize *int32 `json:"executorThreadPoolSize,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=1000
+	// BackendNativeThreadsPoolSize - number of native threads available to the backend drivers for their
+	// blocking calls to the storage arrays, rendered as [backend_defaults] backend_native_threads_pool_size.
+	// The Cinder default applies when not set.
+	BackendNativeThreadsPoolSize *int32 `json:"backendNativeThreadsPoolSize,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	// GracefulShutdownTimeout - seconds given to cinder-volume on pod termination to complete its in-flight
+	// operations (e.g. attachments) before exiting, rendered as [DEFAULT] graceful_shutdown_timeout. The
+	// termination grace period of the pod is extended accordingly. When not set the pod is killed once
+	// the default termination grace period of 30 seconds elapses.
+	GracefulShutdownTimeout *int32 `json:"gracefulShutdownTimeout,omitempty"`
 }
 
 // CinderVolumeSpec defines the desired state of CinderVolume
@@ -63,6 +97,10 @@ type CinderVolumeSpec struct {
 	// +operator-sdk:csv:customresourcedefinitions:type=spec
 	// TLS - Parameters related to the TLS
 	TLS tls.Ca `json:"tls,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// HostSuffix - suffix appended to the cinder-volume service host value
+	HostSuffix string `json:"hostSuffix,omitempty"`
 }
 
 // CinderVolumeStatus defines the observed state of CinderVolume
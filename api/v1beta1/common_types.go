@@ -18,6 +18,7 @@ package v1beta1
 
 import (
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 // CinderTemplate defines common input parameters used by all Cinder services
@@ -41,6 +42,26 @@ type CinderTemplate struct {
 	// +kubebuilder:default={database: CinderDatabasePassword, service: CinderPassword}
 	// PasswordSelectors - Selectors to identify the DB and ServiceUser password from the Secret
 	PasswordSelectors PasswordSelector `json:"passwordSelectors"`
+
+	// +kubebuilder:validation:Optional
+	// TransportTLSSecret - Secret holding the CA (ca.crt), client certificate (tls.crt) and key (tls.key)
+	// used by the Cinder services to connect to a TLS enabled RabbitMQ
+	TransportTLSSecret string `json:"transportTLSSecret,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// GlanceCASecret - Secret holding the CA (ca.crt) used by the Cinder services to verify the
+	// certificate of the Glance API, rendered as glance_ca_certificates_file
+	GlanceCASecret string `json:"glanceCASecret,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// HostAliasesConfigMap - ConfigMap mapping hostnames to IP addresses, added to the /etc/hosts of the
+	// Cinder pods. The pods are rolled out again when the ConfigMap changes.
+	HostAliasesConfigMap string `json:"hostAliasesConfigMap,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// RequeueInterval - interval at which the CR is reconciled again while waiting on a dependency,
+	// e.g. a Secret or the Memcached instance. Overrides the requeue-interval of the operator.
+	RequeueInterval *metav1.Duration `json:"requeueInterval,omitempty"`
 }
 
 // CinderServiceTemplate defines the input parameters that can be defined for a given
@@ -80,6 +101,13 @@ type CinderServiceTemplate struct {
 	// +kubebuilder:validation:Optional
 	// NetworkAttachments is a list of NetworkAttachment resource names to expose the services to the given network
 	NetworkAttachments []string `json:"networkAttachments,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default="/var/lib/kolla/config_files/config.json"
+	// KollaConfigFile - path of the kolla config file kolla_set_configs copies the service config files
+	// from, passed to the container as KOLLA_CONFIG_FILE. Only needs changing for images with a
+	// non-default kolla layout.
+	KollaConfigFile string `json:"kollaConfigFile"`
 }
 
 // PasswordSelector to identify the DB and AdminUser password from the Secret
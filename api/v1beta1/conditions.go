@@ -32,10 +32,55 @@ const (
 
 	// CinderVolumeReadyCondition Status=True condition which indicates if the CinderVolume is configured and operational
 	CinderVolumeReadyCondition condition.Type = "CinderVolumeReady"
+
+	// CinderAPICanaryRolloutCondition Status=True condition which indicates if the last CinderAPI canary rollout succeeded
+	CinderAPICanaryRolloutCondition condition.Type = "CinderAPICanaryRollout"
+
+	// RolloutDeferredCondition Status=True condition which indicates that a pending rollout is
+	// withheld by the defer-rollout or maintenance-window annotations
+	RolloutDeferredCondition condition.Type = "RolloutDeferred"
+
+	// RolloutStuckCondition Status=True condition which indicates that the rollout of the
+	// StatefulSet did not complete within its progress deadline
+	RolloutStuckCondition condition.Type = "RolloutStuck"
+
+	// ImageDriftCondition Status=True condition which indicates that the container image is
+	// referenced by a mutable tag, or that pods run another digest than the pinned one
+	ImageDriftCondition condition.Type = "ImageDrift"
+
+	// CinderV3EndpointReadyCondition Status=True condition which indicates if the Cinder V3 endpoints are exposed
+	CinderV3EndpointReadyCondition condition.Type = "CinderV3EndpointReady"
+
+	// CinderAPIServiceMonitorReadyCondition Status=True condition which indicates if the ServiceMonitor
+	// of the cinder-api metrics is created
+	CinderAPIServiceMonitorReadyCondition condition.Type = "CinderAPIServiceMonitorReady"
+
+	// DryRunCondition Status=True condition which indicates that the resources are not applied, but
+	// only reported as planned changes
+	DryRunCondition condition.Type = "DryRun"
+
+	// ReconciliationPausedCondition Status=True condition which indicates that the reconciliation
+	// is halted by the Paused field of the spec
+	ReconciliationPausedCondition condition.Type = "ReconciliationPaused"
 )
 
 // Cinder Reasons used by API objects.
-const ()
+const (
+	// MutableImageTagReason - the container image is referenced by a tag instead of a digest
+	MutableImageTagReason condition.Reason = "MutableImageTag"
+
+	// ImageDigestMismatchReason - a pod runs another image digest than the one of the spec
+	ImageDigestMismatchReason condition.Reason = "ImageDigestMismatch"
+
+	// ParentInputMissingReason - an input expected from the parent Cinder does not exist
+	ParentInputMissingReason condition.Reason = "ParentInputMissing"
+
+	// SecretMissingReason - a Secret referenced by the spec does not exist
+	SecretMissingReason condition.Reason = "SecretMissing"
+
+	// SecretKeyMissingReason - a key selected in a Secret referenced by the spec does not exist
+	SecretKeyMissingReason condition.Reason = "SecretKeyMissing"
+)
 
 // Common Messages used by API objects.
 const (
@@ -77,4 +122,115 @@ const (
 
 	// CinderVolumeReadyRunningMessage
 	CinderVolumeReadyRunningMessage = "CinderVolume deployments in progress"
+
+	//
+	// InputReady condition messages
+	//
+	// InputReadySecretMissingMessage
+	InputReadySecretMissingMessage = "Input data not ready: Secret %s not found"
+
+	// InputReadySecretKeyMissingMessage
+	InputReadySecretKeyMissingMessage = "Input data not ready: key %s not found in Secret %s"
+
+	// InputReadyParentSecretMissingMessage
+	InputReadyParentSecretMissingMessage = "Input data not ready: Secret %s of the parent Cinder %s not found"
+
+	//
+	// CinderAPICanaryRollout condition messages
+	//
+	// CinderAPICanaryRolloutMessage
+	CinderAPICanaryRolloutMessage = "CinderAPI canary rollout completed"
+
+	// CinderAPICanaryRolloutRunningMessage
+	CinderAPICanaryRolloutRunningMessage = "CinderAPI canary rollout in progress"
+
+	// CinderAPICanaryRolloutErrorMessage
+	CinderAPICanaryRolloutErrorMessage = "CinderAPI canary replica %s failed, rollout halted"
+
+	//
+	// CinderV3EndpointReady condition messages
+	//
+	// CinderV3EndpointReadyInitMessage
+	CinderV3EndpointReadyInitMessage = "CinderV3 endpoints not exposed"
+
+	// CinderV3EndpointReadyMessage
+	CinderV3EndpointReadyMessage = "CinderV3 endpoints exposed"
+
+	// CinderV3EndpointReadyRunningMessage
+	CinderV3EndpointReadyRunningMessage = "CinderV3 endpoints exposure in progress"
+
+	// CinderV3EndpointReadyErrorMessage
+	CinderV3EndpointReadyErrorMessage = "CinderV3 endpoints error occured %s"
+
+	//
+	// DeploymentReady condition messages
+	//
+	// DeploymentPausedMessage
+	DeploymentPausedMessage = "Deployment paused by the " + PauseStatefulSetAnnotation + " annotation"
+
+	// DeploymentRolloutProgressMessage
+	DeploymentRolloutProgressMessage = "Deployment in progress: %d/%d replicas ready, %d updated"
+
+	//
+	// RolloutDeferred condition messages
+	//
+	// RolloutDeferredMessage
+	RolloutDeferredMessage = "Rollout deferred until the maintenance window opens or the defer-rollout annotation is cleared"
+
+	//
+	// OrderedDeployment condition messages
+	//
+	// DeploymentWaitingOnMessage
+	DeploymentWaitingOnMessage = "Waiting for %s to be Ready"
+
+	//
+	// ImageDrift condition messages
+	//
+	// ImageMutableTagMessage
+	ImageMutableTagMessage = "Image %s is not pinned by digest"
+
+	// ImageDigestMismatchMessage
+	ImageDigestMismatchMessage = "Pod %s runs image %s instead of digest %s"
+
+	//
+	// RolloutStuck condition messages
+	//
+	// RolloutStuckMessage
+	RolloutStuckMessage = "Rollout of %s did not complete within %d seconds"
+
+	//
+	// CinderAPIServiceMonitorReady condition messages
+	//
+	// CinderAPIServiceMonitorReadyMessage
+	CinderAPIServiceMonitorReadyMessage = "CinderAPI ServiceMonitor created"
+
+	// CinderAPIServiceMonitorReadyCRDMissingMessage
+	CinderAPIServiceMonitorReadyCRDMissingMessage = "ServiceMonitor CRD is not installed, the CinderAPI metrics are not scraped"
+
+	// CinderAPIServiceMonitorReadyErrorMessage
+	CinderAPIServiceMonitorReadyErrorMessage = "CinderAPI ServiceMonitor error occured %s"
+
+	//
+	// ReconciliationPaused condition messages
+	//
+	// ReconciliationPausedMessage
+	ReconciliationPausedMessage = "Reconciliation paused, the resources are not touched until resumed"
+
+	//
+	// DryRun condition messages
+	//
+	// DryRunMessage
+	DryRunMessage = "Dry run, nothing applied. Planned changes: %s"
+
+	//
+	// KeystoneServiceReady condition messages
+	//
+	// KeystoneServiceNotRegisteredMessage
+	KeystoneServiceNotRegisteredMessage = "Keystone services not registered in the catalog yet: %s"
+
+	// KeystoneServiceAdoptWaitingMessage
+	KeystoneServiceAdoptWaitingMessage = "Waiting for the KeystoneService %s to be registered out-of-band"
+
+	// KeystoneServiceAdoptedMessage
+	KeystoneServiceAdoptedMessage = "Keystone services and endpoints registered out-of-band adopted"
 )
@@ -25,9 +25,38 @@ import (
 	"github.com/openstack-k8s-operators/lib-common/modules/common/condition"
 	"github.com/openstack-k8s-operators/lib-common/modules/common/service"
 	"github.com/openstack-k8s-operators/lib-common/modules/storage"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *APIAutoscaling) DeepCopyInto(out *APIAutoscaling) {
+	*out = *in
+	if in.MinReplicas != nil {
+		in, out := &in.MinReplicas, &out.MinReplicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.TargetCPUUtilization != nil {
+		in, out := &in.TargetCPUUtilization, &out.TargetCPUUtilization
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new APIAutoscaling.
+func (in *APIAutoscaling) DeepCopy() *APIAutoscaling {
+	if in == nil {
+		return nil
+	}
+	out := new(APIAutoscaling)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *APIOverrideSpec) DeepCopyInto(out *APIOverrideSpec) {
 	*out = *in
@@ -50,6 +79,36 @@ func (in *APIOverrideSpec) DeepCopy() *APIOverrideSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *APISecurityContext) DeepCopyInto(out *APISecurityContext) {
+	*out = *in
+	if in.RunAsUser != nil {
+		in, out := &in.RunAsUser, &out.RunAsUser
+		*out = new(int64)
+		**out = **in
+	}
+	if in.RunAsGroup != nil {
+		in, out := &in.RunAsGroup, &out.RunAsGroup
+		*out = new(int64)
+		**out = **in
+	}
+	if in.FSGroup != nil {
+		in, out := &in.FSGroup, &out.FSGroup
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new APISecurityContext.
+func (in *APISecurityContext) DeepCopy() *APISecurityContext {
+	if in == nil {
+		return nil
+	}
+	out := new(APISecurityContext)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Cinder) DeepCopyInto(out *Cinder) {
 	*out = *in
@@ -139,7 +198,7 @@ func (in *CinderAPIList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CinderAPISpec) DeepCopyInto(out *CinderAPISpec) {
 	*out = *in
-	out.CinderTemplate = in.CinderTemplate
+	in.CinderTemplate.DeepCopyInto(&out.CinderTemplate)
 	in.CinderAPITemplate.DeepCopyInto(&out.CinderAPITemplate)
 	if in.ExtraMounts != nil {
 		in, out := &in.ExtraMounts, &out.ExtraMounts
@@ -216,6 +275,10 @@ func (in *CinderAPIStatus) DeepCopyInto(out *CinderAPIStatus) {
 			(*out)[key] = outVal
 		}
 	}
+	if in.RolloutStartTime != nil {
+		in, out := &in.RolloutStartTime, &out.RolloutStartTime
+		*out = (*in).DeepCopy()
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CinderAPIStatus.
@@ -239,6 +302,136 @@ func (in *CinderAPITemplate) DeepCopyInto(out *CinderAPITemplate) {
 	}
 	in.Override.DeepCopyInto(&out.Override)
 	in.TLS.DeepCopyInto(&out.TLS)
+	if in.ExtraCaBundleSecretNames != nil {
+		in, out := &in.ExtraCaBundleSecretNames, &out.ExtraCaBundleSecretNames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.UpdateStrategy != nil {
+		in, out := &in.UpdateStrategy, &out.UpdateStrategy
+		*out = new(appsv1.StatefulSetUpdateStrategy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.KeystoneServiceEnabled != nil {
+		in, out := &in.KeystoneServiceEnabled, &out.KeystoneServiceEnabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.ExposePublic != nil {
+		in, out := &in.ExposePublic, &out.ExposePublic
+		*out = new(bool)
+		**out = **in
+	}
+	if in.KeystoneServiceDescriptions != nil {
+		in, out := &in.KeystoneServiceDescriptions, &out.KeystoneServiceDescriptions
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.PortNames != nil {
+		in, out := &in.PortNames, &out.PortNames
+		*out = make(map[service.Endpoint]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.APIPorts != nil {
+		in, out := &in.APIPorts, &out.APIPorts
+		*out = make(map[service.Endpoint]int32, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.APIWorkers != nil {
+		in, out := &in.APIWorkers, &out.APIWorkers
+		*out = new(int32)
+		**out = **in
+	}
+	in.ProbeConfig.DeepCopyInto(&out.ProbeConfig)
+	if in.ContainerCommand != nil {
+		in, out := &in.ContainerCommand, &out.ContainerCommand
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ContainerArgs != nil {
+		in, out := &in.ContainerArgs, &out.ContainerArgs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.SecurityContext.DeepCopyInto(&out.SecurityContext)
+	if in.TerminationGracePeriodSeconds != nil {
+		in, out := &in.TerminationGracePeriodSeconds, &out.TerminationGracePeriodSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.MaxUnavailable != nil {
+		in, out := &in.MaxUnavailable, &out.MaxUnavailable
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+	if in.TopologySpreadConstraints != nil {
+		in, out := &in.TopologySpreadConstraints, &out.TopologySpreadConstraints
+		*out = make([]v1.TopologySpreadConstraint, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]v1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Autoscaling != nil {
+		in, out := &in.Autoscaling, &out.Autoscaling
+		*out = new(APIAutoscaling)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ExtraEnv != nil {
+		in, out := &in.ExtraEnv, &out.ExtraEnv
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.PodLabels != nil {
+		in, out := &in.PodLabels, &out.PodLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.PodAnnotations != nil {
+		in, out := &in.PodAnnotations, &out.PodAnnotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ImagePullSecrets != nil {
+		in, out := &in.ImagePullSecrets, &out.ImagePullSecrets
+		*out = make([]v1.LocalObjectReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.ExtraContainers != nil {
+		in, out := &in.ExtraContainers, &out.ExtraContainers
+		*out = make([]v1.Container, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.LogResources != nil {
+		in, out := &in.LogResources, &out.LogResources
+		*out = new(v1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.WaitForDatabase != nil {
+		in, out := &in.WaitForDatabase, &out.WaitForDatabase
+		*out = new(bool)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CinderAPITemplate.
@@ -313,7 +506,7 @@ func (in *CinderBackupList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CinderBackupSpec) DeepCopyInto(out *CinderBackupSpec) {
 	*out = *in
-	out.CinderTemplate = in.CinderTemplate
+	in.CinderTemplate.DeepCopyInto(&out.CinderTemplate)
 	in.CinderBackupTemplate.DeepCopyInto(&out.CinderBackupTemplate)
 	if in.ExtraMounts != nil {
 		in, out := &in.ExtraMounts, &out.ExtraMounts
@@ -546,7 +739,7 @@ func (in *CinderSchedulerList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CinderSchedulerSpec) DeepCopyInto(out *CinderSchedulerSpec) {
 	*out = *in
-	out.CinderTemplate = in.CinderTemplate
+	in.CinderTemplate.DeepCopyInto(&out.CinderTemplate)
 	in.CinderSchedulerTemplate.DeepCopyInto(&out.CinderSchedulerTemplate)
 	if in.ExtraMounts != nil {
 		in, out := &in.ExtraMounts, &out.ExtraMounts
@@ -685,8 +878,13 @@ func (in *CinderServiceTemplate) DeepCopy() *CinderServiceTemplate {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CinderSpec) DeepCopyInto(out *CinderSpec) {
 	*out = *in
-	out.CinderTemplate = in.CinderTemplate
+	in.CinderTemplate.DeepCopyInto(&out.CinderTemplate)
 	out.Debug = in.Debug
+	if in.PreserveDatabase != nil {
+		in, out := &in.PreserveDatabase, &out.PreserveDatabase
+		*out = new(bool)
+		**out = **in
+	}
 	in.CinderAPI.DeepCopyInto(&out.CinderAPI)
 	in.CinderScheduler.DeepCopyInto(&out.CinderScheduler)
 	in.CinderBackup.DeepCopyInto(&out.CinderBackup)
@@ -712,6 +910,22 @@ func (in *CinderSpec) DeepCopyInto(out *CinderSpec) {
 		}
 	}
 	out.DBPurge = in.DBPurge
+	if in.RPCResponseTimeout != nil {
+		in, out := &in.RPCResponseTimeout, &out.RPCResponseTimeout
+		*out = new(int32)
+		**out = **in
+	}
+	if in.AllowAvailabilityZoneFallback != nil {
+		in, out := &in.AllowAvailabilityZoneFallback, &out.AllowAvailabilityZoneFallback
+		*out = new(bool)
+		**out = **in
+	}
+	in.KeystoneAuthtokenCache.DeepCopyInto(&out.KeystoneAuthtokenCache)
+	if in.ServiceAccountImagePullSecrets != nil {
+		in, out := &in.ServiceAccountImagePullSecrets, &out.ServiceAccountImagePullSecrets
+		*out = make([]v1.LocalObjectReference, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CinderSpec.
@@ -772,6 +986,21 @@ func (in *CinderStatus) DeepCopyInto(out *CinderStatus) {
 			(*out)[key] = val
 		}
 	}
+	if in.DeploymentWaitingOn != nil {
+		in, out := &in.DeploymentWaitingOn, &out.DeploymentWaitingOn
+		*out = make(map[string][]string, len(*in))
+		for key, val := range *in {
+			var outVal []string
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				in, out := &val, &outVal
+				*out = make([]string, len(*in))
+				copy(*out, *in)
+			}
+			(*out)[key] = outVal
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CinderStatus.
@@ -788,6 +1017,11 @@ func (in *CinderStatus) DeepCopy() *CinderStatus {
 func (in *CinderTemplate) DeepCopyInto(out *CinderTemplate) {
 	*out = *in
 	out.PasswordSelectors = in.PasswordSelectors
+	if in.RequeueInterval != nil {
+		in, out := &in.RequeueInterval, &out.RequeueInterval
+		*out = new(metav1.Duration)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CinderTemplate.
@@ -862,7 +1096,7 @@ func (in *CinderVolumeList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CinderVolumeSpec) DeepCopyInto(out *CinderVolumeSpec) {
 	*out = *in
-	out.CinderTemplate = in.CinderTemplate
+	in.CinderTemplate.DeepCopyInto(&out.CinderTemplate)
 	in.CinderVolumeTemplate.DeepCopyInto(&out.CinderVolumeTemplate)
 	if in.ExtraMounts != nil {
 		in, out := &in.ExtraMounts, &out.ExtraMounts
@@ -937,6 +1171,51 @@ func (in *CinderVolumeTemplate) DeepCopyInto(out *CinderVolumeTemplate) {
 		*out = new(int32)
 		**out = **in
 	}
+	if in.BackendPools != nil {
+		in, out := &in.BackendPools, &out.BackendPools
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ReplicationDevices != nil {
+		in, out := &in.ReplicationDevices, &out.ReplicationDevices
+		*out = make(map[string][]map[string]string, len(*in))
+		for key, val := range *in {
+			var outVal []map[string]string
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				in, out := &val, &outVal
+				*out = make([]map[string]string, len(*in))
+				for i := range *in {
+					if (*in)[i] != nil {
+						in, out := &(*in)[i], &(*out)[i]
+						*out = make(map[string]string, len(*in))
+						for key, val := range *in {
+							(*out)[key] = val
+						}
+					}
+				}
+			}
+			(*out)[key] = outVal
+		}
+	}
+	if in.ExecutorThreadPoolSize != nil {
+		in, out := &in.ExecutorThreadPoolSize, &out.ExecutorThreadPoolSize
+		*out = new(int32)
+		**out = **in
+	}
+	if in.BackendNativeThreadsPoolSize != nil {
+		in, out := &in.BackendNativeThreadsPoolSize, &out.BackendNativeThreadsPoolSize
+		*out = new(int32)
+		**out = **in
+	}
+	if in.GracefulShutdownTimeout != nil {
+		in, out := &in.GracefulShutdownTimeout, &out.GracefulShutdownTimeout
+		*out = new(int32)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CinderVolumeTemplate.
@@ -964,6 +1243,26 @@ func (in *DBPurge) DeepCopy() *DBPurge {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KeystoneAuthtokenCache) DeepCopyInto(out *KeystoneAuthtokenCache) {
+	*out = *in
+	if in.TokenCacheTime != nil {
+		in, out := &in.TokenCacheTime, &out.TokenCacheTime
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KeystoneAuthtokenCache.
+func (in *KeystoneAuthtokenCache) DeepCopy() *KeystoneAuthtokenCache {
+	if in == nil {
+		return nil
+	}
+	out := new(KeystoneAuthtokenCache)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PasswordSelector) DeepCopyInto(out *PasswordSelector) {
 	*out = *in
@@ -978,3 +1277,53 @@ func (in *PasswordSelector) DeepCopy() *PasswordSelector {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProbeConfig) DeepCopyInto(out *ProbeConfig) {
+	*out = *in
+	if in.LivenessTimeout != nil {
+		in, out := &in.LivenessTimeout, &out.LivenessTimeout
+		*out = new(int32)
+		**out = **in
+	}
+	if in.ReadinessTimeout != nil {
+		in, out := &in.ReadinessTimeout, &out.ReadinessTimeout
+		*out = new(int32)
+		**out = **in
+	}
+	if in.PeriodSeconds != nil {
+		in, out := &in.PeriodSeconds, &out.PeriodSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.InitialDelaySeconds != nil {
+		in, out := &in.InitialDelaySeconds, &out.InitialDelaySeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.FailureThreshold != nil {
+		in, out := &in.FailureThreshold, &out.FailureThreshold
+		*out = new(int32)
+		**out = **in
+	}
+	if in.StartupFailureThreshold != nil {
+		in, out := &in.StartupFailureThreshold, &out.StartupFailureThreshold
+		*out = new(int32)
+		**out = **in
+	}
+	if in.StartupPeriodSeconds != nil {
+		in, out := &in.StartupPeriodSeconds, &out.StartupPeriodSeconds
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProbeConfig.
+func (in *ProbeConfig) DeepCopy() *ProbeConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ProbeConfig)
+	in.DeepCopyInto(out)
+	return out
+}
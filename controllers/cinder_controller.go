@@ -19,18 +19,24 @@ package controllers
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	k8s_errors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	"github.com/go-logr/logr"
@@ -41,6 +47,7 @@ import (
 	keystonev1 "github.com/openstack-k8s-operators/keystone-operator/api/v1beta1"
 	"github.com/openstack-k8s-operators/lib-common/modules/common"
 	"github.com/openstack-k8s-operators/lib-common/modules/common/condition"
+	"github.com/openstack-k8s-operators/lib-common/modules/common/configmap"
 	cronjob "github.com/openstack-k8s-operators/lib-common/modules/common/cronjob"
 	"github.com/openstack-k8s-operators/lib-common/modules/common/endpoint"
 	"github.com/openstack-k8s-operators/lib-common/modules/common/env"
@@ -51,9 +58,11 @@ import (
 	common_rbac "github.com/openstack-k8s-operators/lib-common/modules/common/rbac"
 	"github.com/openstack-k8s-operators/lib-common/modules/common/secret"
 	"github.com/openstack-k8s-operators/lib-common/modules/common/service"
+	"github.com/openstack-k8s-operators/lib-common/modules/common/statefulset"
 	"github.com/openstack-k8s-operators/lib-common/modules/common/util"
 	mariadbv1 "github.com/openstack-k8s-operators/mariadb-operator/api/v1beta1"
 
+	appsv1 "k8s.io/api/apps/v1"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
@@ -78,8 +87,11 @@ func (r *CinderReconciler) GetScheme() *runtime.Scheme {
 // CinderReconciler reconciles a Cinder object
 type CinderReconciler struct {
 	client.Client
-	Kclient kubernetes.Interface
-	Scheme  *runtime.Scheme
+	Kclient             kubernetes.Interface
+	Scheme              *runtime.Scheme
+	ReconcileTimeout    time.Duration
+	RequeueInterval     time.Duration
+	DefaultNodeSelector map[string]string
 }
 
 // GetLogger returns a logger object with a logging prefix of "controller.name" and additional controller context fields
@@ -157,6 +169,9 @@ func (r *CinderReconciler) Reconcile(ctx context.Context, req ctrl.Request) (res
 			instance.Status.Conditions.MarkTrue(condition.ReadyCondition, condition.ReadyMessage)
 		}
 
+		// expose why the reconcile gets retried, cleared once it completes
+		instance.Status.RequeueReason = requeueReason(result, _err, instance.Status.Conditions)
+
 		err := helper.PatchInstance(ctx, instance)
 		if err != nil {
 			_err = err
@@ -209,13 +224,18 @@ func (r *CinderReconciler) Reconcile(ctx context.Context, req ctrl.Request) (res
 		instance.Status.CinderVolumesReadyCounts = map[string]int32{}
 	}
 
+	// Bound the reconcile steps, the instance is still patched with the
+	// original context when the deadline is exceeded
+	reconcileCtx, cancel := withReconcileTimeout(ctx, r.ReconcileTimeout)
+	defer cancel()
+
 	// Handle service delete
 	if !instance.DeletionTimestamp.IsZero() {
-		return r.reconcileDelete(ctx, instance, helper)
+		return r.reconcileDelete(reconcileCtx, instance, helper)
 	}
 
 	// Handle non-deleted clusters
-	return r.reconcileNormal(ctx, instance, helper)
+	return r.reconcileNormal(reconcileCtx, instance, helper)
 }
 
 // fields to index to reconcile when change
@@ -224,18 +244,49 @@ const (
 	caBundleSecretNameField = ".spec.tls.caBundleSecretName"
 	tlsAPIInternalField     = ".spec.tls.api.internal.secretName"
 	tlsAPIPublicField       = ".spec.tls.api.public.secretName"
+	transportTLSSecretField = ".spec.transportTLSSecret"
+	glanceCASecretField     = ".spec.glanceCASecret"
+
+	extraCABundleSecretsField = ".spec.extraCaBundleSecretNames"
+
+	hostAliasesConfigMapField = ".spec.hostAliasesConfigMap"
+)
+
+// serviceDependencies - services which have to be Ready before a service gets
+// created when OrderedDeployment is enabled, and the parent conditions mirroring
+// their readiness
+var (
+	serviceDependencies = map[string][]string{
+		"cinderVolume": {"cinderAPI", "cinderScheduler"},
+		"cinderBackup": {"cinderVolume"},
+	}
+	serviceReadyConditions = map[string]condition.Type{
+		"cinderAPI":       cinderv1beta1.CinderAPIReadyCondition,
+		"cinderScheduler": cinderv1beta1.CinderSchedulerReadyCondition,
+		"cinderVolume":    cinderv1beta1.CinderVolumeReadyCondition,
+	}
 )
 
 var (
+	cinderWatchFields = []string{
+		passwordSecretField,
+	}
 	commonWatchFields = []string{
 		passwordSecretField,
 		caBundleSecretNameField,
+		transportTLSSecretField,
+		glanceCASecretField,
+		hostAliasesConfigMapField,
 	}
 	cinderAPIWatchFields = []string{
 		passwordSecretField,
 		caBundleSecretNameField,
 		tlsAPIInternalField,
 		tlsAPIPublicField,
+		transportTLSSecretField,
+		glanceCASecretField,
+		hostAliasesConfigMapField,
+		extraCABundleSecretsField,
 	}
 )
 
@@ -250,8 +301,6 @@ func (r *CinderReconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manage
 	// someone could randomly label a secret "something-cinder-transport" where "something" actually
 	// matches the name of an existing Cinder CR.  In that case changes to that secret would trigger
 	// reconciliation for a Cinder CR that does not need it.
-	//
-	// TODO: We also need a watch func to monitor for changes to the secret referenced by Cinder.Spec.Secret
 	transportURLSecretFn := func(ctx context.Context, o client.Object) []reconcile.Request {
 		result := []reconcile.Request{}
 
@@ -319,6 +368,18 @@ func (r *CinderReconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manage
 		return nil
 	}
 
+	// index passwordSecretField
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &cinderv1beta1.Cinder{}, passwordSecretField, func(rawObj client.Object) []string {
+		// Extract the secret name from the spec, if one is provided
+		cr := rawObj.(*cinderv1beta1.Cinder)
+		if cr.Spec.Secret == "" {
+			return nil
+		}
+		return []string{cr.Spec.Secret}
+	}); err != nil {
+		return err
+	}
+
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&cinderv1beta1.Cinder{}).
 		Owns(&mariadbv1.MariaDBDatabase{}).
@@ -339,9 +400,48 @@ func (r *CinderReconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manage
 			handler.EnqueueRequestsFromMapFunc(transportURLSecretFn)).
 		Watches(&memcachedv1.Memcached{},
 			handler.EnqueueRequestsFromMapFunc(memcachedFn)).
+		// Watch for the secrets referenced by Cinder CRs (e.g. Cinder.Spec.Secret)
+		Watches(
+			&corev1.Secret{},
+			handler.EnqueueRequestsFromMapFunc(r.findObjectsForSrc),
+			builder.WithPredicates(predicate.ResourceVersionChangedPredicate{}),
+		).
 		Complete(r)
 }
 
+func (r *CinderReconciler) findObjectsForSrc(ctx context.Context, src client.Object) []reconcile.Request {
+	requests := []reconcile.Request{}
+
+	l := log.FromContext(context.Background()).WithName("Controllers").WithName("Cinder")
+
+	for _, field := range cinderWatchFields {
+		crList := &cinderv1beta1.CinderList{}
+		listOps := &client.ListOptions{
+			FieldSelector: fields.OneTermEqualSelector(field, src.GetName()),
+			Namespace:     src.GetNamespace(),
+		}
+		err := r.List(context.TODO(), crList, listOps)
+		if err != nil {
+			return []reconcile.Request{}
+		}
+
+		for _, item := range crList.Items {
+			l.Info(fmt.Sprintf("input source %s changed, reconcile: %s - %s", src.GetName(), item.GetName(), item.GetNamespace()))
+
+			requests = append(requests,
+				reconcile.Request{
+					NamespacedName: types.NamespacedName{
+						Name:      item.GetName(),
+						Namespace: item.GetNamespace(),
+					},
+				},
+			)
+		}
+	}
+
+	return requests
+}
+
 func (r *CinderReconciler) reconcileDelete(ctx context.Context, instance *cinderv1beta1.Cinder, helper *helper.Helper) (ctrl.Result, error) {
 	Log := r.GetLogger(ctx)
 
@@ -357,6 +457,24 @@ func (r *CinderReconciler) reconcileDelete(ctx context.Context, instance *cinder
 		if err := db.DeleteFinalizer(ctx, helper); err != nil {
 			return ctrl.Result{}, err
 		}
+
+		if instance.Spec.PreserveDatabase == nil || *instance.Spec.PreserveDatabase {
+			// orphan the DB CRs, otherwise their garbage collection makes the
+			// mariadb-operator drop the database anyway
+			orphaned, err := r.orphanDatabase(ctx, instance, helper, db)
+			if err != nil {
+				return ctrl.Result{}, err
+			}
+			if len(orphaned) > 0 {
+				Log.Info(fmt.Sprintf("Preserving the database of '%s', orphaned %s, which have to be deleted by hand once no longer needed",
+					instance.Name, strings.Join(orphaned, ", ")))
+			}
+		} else {
+			ctrlResult, err := r.dropDatabase(ctx, instance, helper, db)
+			if err != nil || (ctrlResult != ctrl.Result{}) {
+				return ctrlResult, err
+			}
+		}
 	}
 
 	// TODO: We might need to control how the sub-services (API, Backup, Scheduler and Volumes) are
@@ -369,6 +487,96 @@ func (r *CinderReconciler) reconcileDelete(ctx context.Context, instance *cinder
 	return ctrl.Result{}, nil
 }
 
+// orphanDatabase - removes the Cinder owner reference from the MariaDBDatabase and
+// MariaDBAccount, so they, and the database, outlive the Cinder CR. Returns the
+// Kind/Name of the orphaned CRs.
+func (r *CinderReconciler) orphanDatabase(
+	ctx context.Context,
+	instance *cinderv1beta1.Cinder,
+	helper *helper.Helper,
+	db *mariadbv1.Database,
+) ([]string, error) {
+	objs := []client.Object{db.GetDatabase()}
+	if db.GetAccount() != nil {
+		objs = append(objs, db.GetAccount())
+	}
+
+	orphaned := []string{}
+	for _, obj := range objs {
+		ownerRefs := []metav1.OwnerReference{}
+		for _, ownerRef := range obj.GetOwnerReferences() {
+			if ownerRef.UID != instance.UID {
+				ownerRefs = append(ownerRefs, ownerRef)
+			}
+		}
+		if len(ownerRefs) == len(obj.GetOwnerReferences()) {
+			continue
+		}
+		obj.SetOwnerReferences(ownerRefs)
+		if err := helper.GetClient().Update(ctx, obj); err != nil {
+			if k8s_errors.IsNotFound(err) {
+				continue
+			}
+			return orphaned, err
+		}
+		util.LogForObject(helper, "Preserving the database, removed the Cinder owner reference", obj)
+		gvk, err := apiutil.GVKForObject(obj, helper.GetScheme())
+		if err != nil {
+			return orphaned, err
+		}
+		orphaned = append(orphaned, fmt.Sprintf("%s/%s", gvk.Kind, obj.GetName()))
+	}
+
+	return orphaned, nil
+}
+
+// dropDatabase - deletes the MariaDBDatabase, which makes the mariadb-operator drop
+// the database, and requeues until it is gone. A database shared with another
+// MariaDBDatabase of the same database instance is left alone.
+func (r *CinderReconciler) dropDatabase(
+	ctx context.Context,
+	instance *cinderv1beta1.Cinder,
+	helper *helper.Helper,
+	db *mariadbv1.Database,
+) (ctrl.Result, error) {
+	Log := r.GetLogger(ctx)
+
+	mariaDBDatabase := db.GetDatabase()
+
+	databases := &mariadbv1.MariaDBDatabaseList{}
+	if err := helper.GetClient().List(ctx, databases, client.InNamespace(instance.Namespace)); err != nil {
+		return ctrl.Result{}, err
+	}
+	for _, sibling := range databases.Items {
+		if sibling.Name != mariaDBDatabase.Name &&
+			sibling.DeletionTimestamp.IsZero() &&
+			sibling.Spec.Name == mariaDBDatabase.Spec.Name &&
+			sibling.Labels["dbName"] == mariaDBDatabase.Labels["dbName"] {
+			Log.Info(fmt.Sprintf("Database %s is shared with MariaDBDatabase %s, not dropping it",
+				mariaDBDatabase.Spec.Name, sibling.Name))
+			_, err := r.orphanDatabase(ctx, instance, helper, db)
+			return ctrl.Result{}, err
+		}
+	}
+
+	if mariaDBDatabase.DeletionTimestamp.IsZero() {
+		if err := helper.GetClient().Delete(ctx, mariaDBDatabase); err != nil && !k8s_errors.IsNotFound(err) {
+			return ctrl.Result{}, err
+		}
+		Log.Info(fmt.Sprintf("Dropping database %s", mariaDBDatabase.Spec.Name))
+	}
+
+	// block the finalizer removal until the mariadb-operator dropped the database
+	err := helper.GetClient().Get(ctx, types.NamespacedName{Name: mariaDBDatabase.Name, Namespace: mariaDBDatabase.Namespace}, &mariadbv1.MariaDBDatabase{})
+	if err == nil {
+		return ctrl.Result{RequeueAfter: cinder.GetRequeueInterval(instance.Spec.CinderTemplate, r.RequeueInterval)}, nil
+	} else if !k8s_errors.IsNotFound(err) {
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{}, nil
+}
+
 func (r *CinderReconciler) reconcileInit(
 	ctx context.Context,
 	instance *cinderv1beta1.Cinder,
@@ -512,6 +720,17 @@ func (r *CinderReconciler) reconcileNormal(ctx context.Context, instance *cinder
 		return rbacResult, nil
 	}
 
+	err = r.serviceAccountImagePullSecrets(ctx, instance, helper)
+	if err != nil {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			condition.ServiceAccountReadyCondition,
+			condition.ErrorReason,
+			condition.SeverityWarning,
+			condition.ServiceAccountReadyErrorMessage,
+			err.Error()))
+		return ctrl.Result{}, err
+	}
+
 	serviceLabels := map[string]string{
 		common.AppSelector: cinder.ServiceName,
 	}
@@ -546,7 +765,7 @@ func (r *CinderReconciler) reconcileNormal(ctx context.Context, instance *cinder
 			condition.RequestedReason,
 			condition.SeverityInfo,
 			condition.RabbitMqTransportURLReadyRunningMessage))
-		return ctrl.Result{RequeueAfter: time.Duration(10) * time.Second}, nil
+		return ctrl.Result{RequeueAfter: cinder.GetRequeueInterval(instance.Spec.CinderTemplate, r.RequeueInterval)}, nil
 	}
 
 	instance.Status.Conditions.MarkTrue(condition.RabbitMqTransportURLReadyCondition, condition.RabbitMqTransportURLReadyMessage)
@@ -564,7 +783,7 @@ func (r *CinderReconciler) reconcileNormal(ctx context.Context, instance *cinder
 				condition.RequestedReason,
 				condition.SeverityInfo,
 				condition.MemcachedReadyWaitingMessage))
-			return ctrl.Result{RequeueAfter: time.Duration(10) * time.Second}, fmt.Errorf("memcached %s not found", instance.Spec.MemcachedInstance)
+			return ctrl.Result{RequeueAfter: cinder.GetRequeueInterval(instance.Spec.CinderTemplate, r.RequeueInterval)}, fmt.Errorf("memcached %s not found", instance.Spec.MemcachedInstance)
 		}
 		instance.Status.Conditions.Set(condition.FalseCondition(
 			condition.MemcachedReadyCondition,
@@ -581,7 +800,7 @@ func (r *CinderReconciler) reconcileNormal(ctx context.Context, instance *cinder
 			condition.RequestedReason,
 			condition.SeverityInfo,
 			condition.MemcachedReadyWaitingMessage))
-		return ctrl.Result{RequeueAfter: time.Duration(10) * time.Second}, fmt.Errorf("memcached %s is not ready", memcached.Name)
+		return ctrl.Result{RequeueAfter: cinder.GetRequeueInterval(instance.Spec.CinderTemplate, r.RequeueInterval)}, fmt.Errorf("memcached %s is not ready", memcached.Name)
 	}
 	// Mark the Memcached Service as Ready if we get to this point with no errors
 	instance.Status.Conditions.MarkTrue(
@@ -596,10 +815,11 @@ func (r *CinderReconciler) reconcileNormal(ctx context.Context, instance *cinder
 		if k8s_errors.IsNotFound(err) {
 			instance.Status.Conditions.Set(condition.FalseCondition(
 				condition.InputReadyCondition,
-				condition.RequestedReason,
+				cinderv1beta1.SecretMissingReason,
 				condition.SeverityInfo,
-				condition.InputReadyWaitingMessage))
-			return ctrl.Result{RequeueAfter: time.Duration(10) * time.Second}, fmt.Errorf("OpenStack secret %s not found", instance.Spec.Secret)
+				cinderv1beta1.InputReadySecretMissingMessage,
+				instance.Spec.Secret))
+			return ctrl.Result{RequeueAfter: cinder.GetRequeueInterval(instance.Spec.CinderTemplate, r.RequeueInterval)}, fmt.Errorf("OpenStack secret %s not found", instance.Spec.Secret)
 		}
 		instance.Status.Conditions.Set(condition.FalseCondition(
 			condition.InputReadyCondition,
@@ -609,6 +829,22 @@ func (r *CinderReconciler) reconcileNormal(ctx context.Context, instance *cinder
 			err.Error()))
 		return ctrl.Result{}, err
 	}
+	// the selected passwords are rendered into the config, a typo would
+	// otherwise silently result in empty passwords
+	if key := cinder.GetMissingSecretKey(
+		ospSecret,
+		instance.Spec.PasswordSelectors.Service,
+		instance.Spec.PasswordSelectors.Database,
+	); key != "" {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			condition.InputReadyCondition,
+			cinderv1beta1.SecretKeyMissingReason,
+			condition.SeverityWarning,
+			cinderv1beta1.InputReadySecretKeyMissingMessage,
+			key,
+			instance.Spec.Secret))
+		return ctrl.Result{RequeueAfter: cinder.GetRequeueInterval(instance.Spec.CinderTemplate, r.RequeueInterval)}, fmt.Errorf("key %s not found in OpenStack secret %s", key, instance.Spec.Secret)
+	}
 	// Add a prefix to the var name to avoid accidental collision with other non-secret vars.
 	configVars["secret-"+ospSecret.Name] = env.SetValue(hash)
 
@@ -665,7 +901,7 @@ func (r *CinderReconciler) reconcileNormal(ctx context.Context, instance *cinder
 					condition.SeverityInfo,
 					condition.NetworkAttachmentsReadyWaitingMessage,
 					netAtt))
-				return ctrl.Result{RequeueAfter: time.Second * 10}, fmt.Errorf("network-attachment-definition %s not found", netAtt)
+				return ctrl.Result{RequeueAfter: cinder.GetRequeueInterval(instance.Spec.CinderTemplate, r.RequeueInterval)}, fmt.Errorf("network-attachment-definition %s not found", netAtt)
 			}
 			instance.Status.Conditions.Set(condition.FalseCondition(
 				condition.NetworkAttachmentsReadyCondition,
@@ -711,6 +947,9 @@ func (r *CinderReconciler) reconcileNormal(ctx context.Context, instance *cinder
 	// normal reconcile tasks
 	//
 
+	// services held by OrderedDeployment are recorded again below
+	instance.Status.DeploymentWaitingOn = map[string][]string{}
+
 	// deploy cinder-api
 	cinderAPI, op, err := r.apiDeploymentCreateOrUpdate(ctx, instance)
 	if err != nil {
@@ -766,7 +1005,24 @@ func (r *CinderReconciler) reconcileNormal(ctx context.Context, instance *cinder
 	// Many OpenStack deployments don't use the cinder-backup service (it's optional),
 	// so there's no need to deploy it unless it's required.
 	var backupCondition *condition.Condition
-	if *instance.Spec.CinderBackup.Replicas > 0 {
+	backupWaitingOn, err := r.waitingOn(ctx, instance, "cinderBackup", &cinderv1beta1.CinderBackup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-backup", instance.Name),
+			Namespace: instance.Namespace,
+		},
+	})
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if *instance.Spec.CinderBackup.Replicas > 0 && len(backupWaitingOn) > 0 {
+		instance.Status.DeploymentWaitingOn["cinderBackup"] = backupWaitingOn
+		backupCondition = condition.FalseCondition(
+			cinderv1beta1.CinderBackupReadyCondition,
+			condition.RequestedReason,
+			condition.SeverityInfo,
+			cinderv1beta1.DeploymentWaitingOnMessage,
+			strings.Join(backupWaitingOn, ", "))
+	} else if *instance.Spec.CinderBackup.Replicas > 0 {
 		cinderBackup, op, err := r.backupDeploymentCreateOrUpdate(ctx, instance)
 		if err != nil {
 			instance.Status.Conditions.Set(condition.FalseCondition(
@@ -808,6 +1064,27 @@ func (r *CinderReconciler) reconcileNormal(ctx context.Context, instance *cinder
 	// deploy cinder-volumes
 	var volumeCondition *condition.Condition
 	for name, volume := range instance.Spec.CinderVolumes {
+		volumeWaitingOn, err := r.waitingOn(ctx, instance, "cinderVolume", &cinderv1beta1.CinderVolume{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprintf("%s-volume-%s", instance.Name, name),
+				Namespace: instance.Namespace,
+			},
+		})
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if len(volumeWaitingOn) > 0 {
+			instance.Status.DeploymentWaitingOn["cinderVolume"] = volumeWaitingOn
+			c = condition.FalseCondition(
+				cinderv1beta1.CinderVolumeReadyCondition,
+				condition.RequestedReason,
+				condition.SeverityInfo,
+				cinderv1beta1.DeploymentWaitingOnMessage,
+				strings.Join(volumeWaitingOn, ", "))
+			volumeCondition = condition.GetHigherPrioCondition(c, volumeCondition).DeepCopy()
+			continue
+		}
+
 		cinderVolume, op, err := r.volumeDeploymentCreateOrUpdate(ctx, instance, name, volume)
 		if err != nil {
 			instance.Status.Conditions.Set(condition.FalseCondition(
@@ -855,7 +1132,7 @@ func (r *CinderReconciler) reconcileNormal(ctx context.Context, instance *cinder
 	}
 
 	// create CronJob
-	cronjobDef := cinder.CronJob(instance, serviceLabels, serviceAnnotations)
+	cronjobDef := cinder.CronJob(instance, serviceLabels, serviceAnnotations, r.DefaultNodeSelector)
 	cronjob := cronjob.NewCronJob(
 		cronjobDef,
 		5*time.Second,
@@ -896,8 +1173,8 @@ func (r *CinderReconciler) reconcileUpgrade(ctx context.Context, instance *cinde
 
 	Log.Info(fmt.Sprintf("Reconciling Service '%s' upgrade", instance.Name))
 
-	// TODO: should have major version upgrade tasks
-	// -delete dbsync hash from status to rerun it?
+	// nothing to do, the db sync Job carries the cinder-api image, so a new
+	// image changes the job hash and the db sync runs again
 
 	Log.Info(fmt.Sprintf("Reconciled Service '%s' upgrade successfully", instance.Name))
 	return ctrl.Result{}, nil
@@ -958,12 +1235,43 @@ func (r *CinderReconciler) generateServiceConfigs(
 		instance.Status.DatabaseHostname,
 		cinder.DatabaseName)
 	templateParameters["MemcachedServersWithInet"] = strings.Join(memcached.Status.ServerListWithInet, ",")
+	templateParameters["QuotaDriver"] = instance.Spec.QuotaDriver
+	if instance.Spec.RPCResponseTimeout != nil {
+		templateParameters["RPCResponseTimeout"] = *instance.Spec.RPCResponseTimeout
+	}
+	templateParameters["DefaultVolumeType"] = instance.Spec.DefaultVolumeType
+	templateParameters["AllowAvailabilityZoneFallback"] = ""
+	if instance.Spec.AllowAvailabilityZoneFallback != nil {
+		templateParameters["AllowAvailabilityZoneFallback"] = strconv.FormatBool(*instance.Spec.AllowAvailabilityZoneFallback)
+	}
+	if instance.Spec.TransportTLSSecret != "" {
+		templateParameters["TransportTLSCertsPath"] = cinder.TransportTLSCertsPath
+	}
+	if instance.Spec.GlanceCASecret != "" {
+		templateParameters["GlanceCACertsPath"] = cinder.GlanceCACertsPath
+	}
+	templateParameters["GlanceAPIInsecure"] = instance.Spec.GlanceAPIInsecure
+
+	tokenCache := instance.Spec.KeystoneAuthtokenCache
+	if tokenCache.TokenCacheTime != nil {
+		templateParameters["TokenCacheTime"] = strconv.Itoa(int(*tokenCache.TokenCacheTime))
+	}
+	if tokenCache.MemcacheSecurityStrategy != "" && tokenCache.MemcacheSecurityStrategy != "None" {
+		secretKey, ok := ospSecret.Data[tokenCache.MemcacheSecretKeySelector]
+		if tokenCache.MemcacheSecretKeySelector == "" || !ok {
+			return fmt.Errorf("memcache secret key %q not found in secret %s, required by the %s memcache security strategy",
+				tokenCache.MemcacheSecretKeySelector, instance.Spec.Secret, tokenCache.MemcacheSecurityStrategy)
+		}
+		templateParameters["MemcacheSecurityStrategy"] = tokenCache.MemcacheSecurityStrategy
+		templateParameters["MemcacheSecretKey"] = string(secretKey)
+	}
 
 	// create httpd  vhost template parameters
 	httpdVhostConfig := map[string]interface{}{}
 	for _, endpt := range []service.Endpoint{service.EndpointInternal, service.EndpointPublic} {
 		endptConfig := map[string]interface{}{}
-		endptConfig["ServerName"] = fmt.Sprintf("%s-%s.%s.svc", cinder.ServiceName, endpt.String(), instance.Namespace)
+		endptConfig["ServerName"] = fmt.Sprintf("%s.%s.svc", cinder.GetAPIServiceName(instance.Spec.CinderAPI, endpt), instance.Namespace)
+		endptConfig["Port"] = cinder.GetAPIPort(instance.Spec.CinderAPI, endpt)
 		endptConfig["TLS"] = false // default TLS to false, and set it bellow to true if enabled
 		if instance.Spec.CinderAPI.TLS.API.Enabled(endpt) {
 			endptConfig["TLS"] = true
@@ -973,6 +1281,8 @@ func (r *CinderReconciler) generateServiceConfigs(
 		httpdVhostConfig[endpt.String()] = endptConfig
 	}
 	templateParameters["VHosts"] = httpdVhostConfig
+	templateParameters["ListenPorts"] = cinder.GetAPIListenPorts(instance.Spec.CinderAPI)
+	templateParameters["APIWorkers"] = cinder.GetAPIWorkers(instance.Spec.CinderAPI)
 
 	configTemplates := []util.Template{
 		{
@@ -1018,6 +1328,7 @@ func (r *CinderReconciler) createHashOfInputHashes(
 		instance.Status.Hash = hashMap
 		Log.Info(fmt.Sprintf("Input maps hash %s - %s", common.InputHashName, hash))
 	}
+	instance.Status.Hash = setInputHashes(instance.Status.Hash, mergedMapVars)
 	return hash, changed, nil
 }
 
@@ -1204,6 +1515,7 @@ func (r *CinderReconciler) volumeDeploymentCreateOrUpdate(ctx context.Context, i
 		TransportURLSecret:   instance.Status.TransportURLSecret,
 		ServiceAccount:       instance.RbacResourceName(),
 		TLS:                  instance.Spec.CinderAPI.TLS.Ca,
+		HostSuffix:           instance.Spec.HostSuffix,
 	}
 
 	deployment := &cinderv1beta1.CinderVolume{
@@ -1272,3 +1584,284 @@ func (r *CinderReconciler) volumeCleanupDeployments(ctx context.Context, instanc
 
 	return nil
 }
+
+// serviceAccountImagePullSecrets attaches the configured pull secrets to the
+// ServiceAccount created by ReconcileRbac. The pull secrets already on the
+// ServiceAccount (e.g. the ones added by OpenShift) are left untouched, while
+// the ones attached by the operator are tracked in an annotation so they can
+// be detached once they are removed from the spec.
+func (r *CinderReconciler) serviceAccountImagePullSecrets(
+	ctx context.Context,
+	instance *cinderv1beta1.Cinder,
+	h *helper.Helper,
+) error {
+	sa := &corev1.ServiceAccount{}
+	err := h.GetClient().Get(ctx, types.NamespacedName{Name: instance.RbacResourceName(), Namespace: instance.RbacNamespace()}, sa)
+	if err != nil {
+		return err
+	}
+
+	managed := map[string]bool{}
+	if names := sa.Annotations[cinderv1beta1.ImagePullSecretsAnnotation]; names != "" {
+		for _, name := range strings.Split(names, ",") {
+			managed[name] = true
+		}
+	}
+	wanted := map[string]bool{}
+	for _, pullSecret := range instance.Spec.ServiceAccountImagePullSecrets {
+		wanted[pullSecret.Name] = true
+	}
+
+	changed := false
+	present := map[string]bool{}
+	pullSecrets := []corev1.LocalObjectReference{}
+	for _, existing := range sa.ImagePullSecrets {
+		if managed[existing.Name] && !wanted[existing.Name] {
+			changed = true
+			continue
+		}
+		present[existing.Name] = true
+		pullSecrets = append(pullSecrets, existing)
+	}
+
+	attached := []string{}
+	for _, pullSecret := range instance.Spec.ServiceAccountImagePullSecrets {
+		if !present[pullSecret.Name] {
+			pullSecrets = append(pullSecrets, pullSecret)
+			present[pullSecret.Name] = true
+			changed = true
+		} else if !managed[pullSecret.Name] {
+			// attached by someone else, not ours to detach
+			continue
+		}
+		attached = append(attached, pullSecret.Name)
+	}
+	sort.Strings(attached)
+
+	if strings.Join(attached, ",") != sa.Annotations[cinderv1beta1.ImagePullSecretsAnnotation] {
+		if len(attached) == 0 {
+			delete(sa.Annotations, cinderv1beta1.ImagePullSecretsAnnotation)
+		} else {
+			if sa.Annotations == nil {
+				sa.Annotations = map[string]string{}
+			}
+			sa.Annotations[cinderv1beta1.ImagePullSecretsAnnotation] = strings.Join(attached, ",")
+		}
+		changed = true
+	}
+	if !changed {
+		return nil
+	}
+	sa.ImagePullSecrets = pullSecrets
+
+	// the operator is only allowed to update serviceaccounts
+	err = h.GetClient().Update(ctx, sa)
+	if err != nil {
+		return err
+	}
+	h.GetLogger().Info(fmt.Sprintf("ServiceAccount %s - imagePullSecrets updated", sa.Name))
+
+	return nil
+}
+
+// rolloutDeferred - returns true if the pod template of an existing StatefulSet
+// changed but its rollout is withheld by the annotations of the instance. The
+// initial deployment of the StatefulSet is never deferred.
+func rolloutDeferred(
+	ctx context.Context,
+	h *helper.Helper,
+	instance client.Object,
+	ssDef *appsv1.StatefulSet,
+) (bool, error) {
+	deferred, err := cinder.IsRolloutDeferred(instance.GetAnnotations(), time.Now())
+	if err != nil || !deferred {
+		return false, err
+	}
+
+	current, err := statefulset.GetStatefulSetWithName(ctx, h, ssDef.Name, ssDef.Namespace)
+	if err != nil {
+		if k8s_errors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return podTemplateChanged(&current.Spec.Template, &ssDef.Spec.Template), nil
+}
+
+// getHostAliases - returns the pod hostAliases defined by the ConfigMap
+// and adds its hash to envVars, so the pods are rolled out when it changes
+func getHostAliases(
+	ctx context.Context,
+	h *helper.Helper,
+	configMapName string,
+	namespace string,
+	envVars *map[string]env.Setter,
+) ([]corev1.HostAlias, error) {
+	cm, hash, err := configmap.GetConfigMapAndHashWithName(ctx, h, configMapName, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	hostAliases, err := cinder.GetHostAliases(cm.Data)
+	if err != nil {
+		return nil, fmt.Errorf("ConfigMap %s: %w", configMapName, err)
+	}
+	(*envVars)["configmap-"+cm.Name] = env.SetValue(hash)
+
+	return hostAliases, nil
+}
+
+// imageDrift - returns an ImageDriftCondition when image is referenced by a
+// mutable tag, or when the containerName container of a pod selected by
+// serviceLabels runs another digest than the one image is pinned to, nil
+// otherwise. A tag is not resolved against the registry, so the digest drift
+// is only checked for images pinned by digest.
+func imageDrift(
+	ctx context.Context,
+	h *helper.Helper,
+	image string,
+	containerName string,
+	namespace string,
+	serviceLabels map[string]string,
+) (*condition.Condition, error) {
+	digest := cinder.GetImageDigest(image)
+	if digest == "" {
+		return &condition.Condition{
+			Type:     cinderv1beta1.ImageDriftCondition,
+			Status:   corev1.ConditionTrue,
+			Reason:   cinderv1beta1.MutableImageTagReason,
+			Severity: condition.SeverityInfo,
+			Message:  fmt.Sprintf(cinderv1beta1.ImageMutableTagMessage, image),
+		}, nil
+	}
+
+	pods := &corev1.PodList{}
+	err := h.GetClient().List(ctx, pods, client.InNamespace(namespace), client.MatchingLabels(serviceLabels))
+	if err != nil {
+		return nil, err
+	}
+	for _, pod := range pods.Items {
+		for _, cs := range pod.Status.ContainerStatuses {
+			// sidecars like the log container can run another image
+			if cs.Name != containerName {
+				continue
+			}
+			// the ImageID is only known once the image got pulled
+			if cs.ImageID == "" || cinder.GetImageDigest(cs.ImageID) == digest {
+				continue
+			}
+			return &condition.Condition{
+				Type:     cinderv1beta1.ImageDriftCondition,
+				Status:   corev1.ConditionTrue,
+				Reason:   cinderv1beta1.ImageDigestMismatchReason,
+				Severity: condition.SeverityWarning,
+				Message:  fmt.Sprintf(cinderv1beta1.ImageDigestMismatchMessage, pod.Name, cs.ImageID, digest),
+			}, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// changedInputs - returns the sorted names of the inputs (secrets, config
+// maps, ...) whose hash differs from the one stored by setInputHashes,
+// including the inputs which got added or removed since
+func changedInputs(hashes map[string]string, inputs []corev1.EnvVar) []string {
+	names := []string{}
+	current := map[string]bool{}
+	for _, input := range inputs {
+		key := cinderv1beta1.InputHashPrefix + input.Name
+		current[key] = true
+		if hashes[key] != input.Value {
+			names = append(names, input.Name)
+		}
+	}
+	for key := range hashes {
+		if strings.HasPrefix(key, cinderv1beta1.InputHashPrefix) && !current[key] {
+			names = append(names, strings.TrimPrefix(key, cinderv1beta1.InputHashPrefix))
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// setInputHashes - stores the hash of each input next to the merged one, so
+// the status tells which input changed when the pods got rolled out. The
+// hashes of the inputs which are no longer used are removed.
+func setInputHashes(hashes map[string]string, inputs []corev1.EnvVar) map[string]string {
+	if hashes == nil {
+		hashes = map[string]string{}
+	}
+	current := map[string]bool{}
+	for _, input := range inputs {
+		key := cinderv1beta1.InputHashPrefix + input.Name
+		hashes[key] = input.Value
+		current[key] = true
+	}
+	for key := range hashes {
+		if strings.HasPrefix(key, cinderv1beta1.InputHashPrefix) && !current[key] {
+			delete(hashes, key)
+		}
+	}
+	return hashes
+}
+
+// requeueReason - returns why the reconcile is retried: the error it failed
+// with, or the message of the first condition which is not True yet when it
+// asked to be requeued. Empty when the reconcile completed.
+func requeueReason(result ctrl.Result, err error, conditions condition.Conditions) string {
+	if err != nil {
+		return err.Error()
+	}
+	if !result.Requeue && result.RequeueAfter == 0 {
+		return ""
+	}
+	for _, c := range conditions {
+		if c.Type != condition.ReadyCondition && c.Status == corev1.ConditionFalse {
+			return c.Message
+		}
+	}
+	return ""
+}
+
+// withReconcileTimeout - returns a context bounded by the reconcile timeout, so
+// a stalled external call (Keystone, DB, ...) fails and the request is requeued
+// instead of blocking a worker. No deadline is set when the timeout is 0.
+func withReconcileTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// waitingOn - Returns the dependencies of service which are not Ready yet when
+// OrderedDeployment is enabled and obj, the service's CR, does not exist yet.
+// Existing CRs are never held so that updates keep being applied.
+func (r *CinderReconciler) waitingOn(
+	ctx context.Context,
+	instance *cinderv1beta1.Cinder,
+	service string,
+	obj client.Object,
+) ([]string, error) {
+	if !instance.Spec.OrderedDeployment {
+		return nil, nil
+	}
+
+	err := r.Client.Get(ctx, client.ObjectKeyFromObject(obj), obj)
+	if err == nil {
+		return nil, nil
+	}
+	if !k8s_errors.IsNotFound(err) {
+		return nil, err
+	}
+
+	waiting := []string{}
+	for _, dep := range serviceDependencies[service] {
+		if !instance.Status.Conditions.IsTrue(serviceReadyConditions[dep]) {
+			waiting = append(waiting, dep)
+		}
+	}
+
+	return waiting, nil
+}
@@ -19,15 +19,23 @@ package controllers
 import (
 	"context"
 	"fmt"
+	"net/url"
+	"strings"
 	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
 	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	k8s_errors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
@@ -75,8 +83,12 @@ func (r *CinderAPIReconciler) GetScheme() *runtime.Scheme {
 // CinderAPIReconciler reconciles a CinderAPI object
 type CinderAPIReconciler struct {
 	client.Client
-	Kclient kubernetes.Interface
-	Scheme  *runtime.Scheme
+	Kclient             kubernetes.Interface
+	Scheme              *runtime.Scheme
+	Recorder            record.EventRecorder
+	ReconcileTimeout    time.Duration
+	RequeueInterval     time.Duration
+	DefaultNodeSelector map[string]string
 }
 
 // GetLogger returns a logger object with a logging prefix of "controller.name" and additional controller context fields
@@ -99,7 +111,12 @@ var keystoneServices = []map[string]string{
 // +kubebuilder:rbac:groups=core,resources=services,verbs=get;list;create;update;patch;delete;watch
 // +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;
 // +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;create;update;patch;delete;watch
+// +kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
+// +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch
 // +kubebuilder:rbac:groups=apps,resources=statefulsets,verbs=get;list;create;update;patch;delete;watch
+// +kubebuilder:rbac:groups=policy,resources=poddisruptionbudgets,verbs=get;list;create;update;patch;delete;watch
+// +kubebuilder:rbac:groups=autoscaling,resources=horizontalpodautoscalers,verbs=get;list;create;update;patch;delete;watch
+// +kubebuilder:rbac:groups=monitoring.coreos.com,resources=servicemonitors,verbs=get;list;create;update;patch;delete;watch
 // +kubebuilder:rbac:groups=keystone.openstack.org,resources=keystoneservices,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=keystone.openstack.org,resources=keystoneendpoints,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=k8s.cni.cncf.io,resources=network-attachment-definitions,verbs=get;list;watch
@@ -133,12 +150,19 @@ func (r *CinderAPIReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 		return ctrl.Result{}, err
 	}
 
+	// Save a copy of the conditions so that we can report their transitions
+	savedConditions := instance.Status.Conditions.DeepCopy()
+
 	// Always patch the instance status when exiting this function so we can persist any changes.
 	defer func() {
 		// update the overall status condition if service is ready
 		if instance.IsReady() {
 			instance.Status.Conditions.MarkTrue(condition.ReadyCondition, condition.ReadyMessage)
 		}
+		instance.Status.Summary = statusSummary(
+			instance.Status.Conditions, instance.Status.ReadyCount, instance.ExpectedReplicas())
+
+		r.recordEvents(instance, savedConditions, _err)
 
 		err := helper.PatchInstance(ctx, instance)
 		if err != nil {
@@ -160,6 +184,7 @@ func (r *CinderAPIReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 		// initialize conditions used later as Status=Unknown
 		cl := condition.CreateList(
 			condition.UnknownCondition(condition.ExposeServiceReadyCondition, condition.InitReason, condition.ExposeServiceReadyInitMessage),
+			condition.UnknownCondition(cinderv1beta1.CinderV3EndpointReadyCondition, condition.InitReason, cinderv1beta1.CinderV3EndpointReadyInitMessage),
 			condition.UnknownCondition(condition.InputReadyCondition, condition.InitReason, condition.InputReadyInitMessage),
 			condition.UnknownCondition(condition.ServiceConfigReadyCondition, condition.InitReason, condition.ServiceConfigReadyInitMessage),
 			condition.UnknownCondition(condition.DeploymentReadyCondition, condition.InitReason, condition.DeploymentReadyInitMessage),
@@ -188,13 +213,38 @@ func (r *CinderAPIReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 		instance.Status.NetworkAttachments = map[string][]string{}
 	}
 
+	// Bound the reconcile steps, the instance is still patched with the
+	// original context when the deadline is exceeded
+	reconcileCtx, cancel := withReconcileTimeout(ctx, r.ReconcileTimeout)
+	defer cancel()
+
 	// Handle service delete
 	if !instance.DeletionTimestamp.IsZero() {
-		return r.reconcileDelete(ctx, instance, helper)
+		return r.reconcileDelete(reconcileCtx, instance, helper)
+	}
+
+	// Leave the resources alone while paused
+	if instance.Spec.Paused {
+		Log.Info(fmt.Sprintf("Reconciliation of '%s' is paused", instance.Name))
+		instance.Status.Conditions.MarkTrue(
+			cinderv1beta1.ReconciliationPausedCondition,
+			cinderv1beta1.ReconciliationPausedMessage)
+		return ctrl.Result{}, nil
+	}
+	instance.Status.Conditions.Remove(cinderv1beta1.ReconciliationPausedCondition)
+
+	// Only report the planned changes of a dry run
+	if instance.Spec.DryRun {
+		instance.Status.Conditions.MarkTrue(
+			cinderv1beta1.DryRunCondition,
+			cinderv1beta1.DryRunMessage,
+			strings.Join(plannedChanges(instance), "; "))
+		return ctrl.Result{}, nil
 	}
+	instance.Status.Conditions.Remove(cinderv1beta1.DryRunCondition)
 
 	// Handle non-deleted clusters
-	return r.reconcileNormal(ctx, instance, helper)
+	return r.reconcileNormal(reconcileCtx, instance, helper)
 }
 
 // SetupWithManager sets up the controller with the Manager.
@@ -280,6 +330,51 @@ func (r *CinderAPIReconciler) SetupWithManager(ctx context.Context, mgr ctrl.Man
 		return err
 	}
 
+	// index extraCABundleSecretsField
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &cinderv1beta1.CinderAPI{}, extraCABundleSecretsField, func(rawObj client.Object) []string {
+		// Extract the secret names from the spec, if any are provided
+		cr := rawObj.(*cinderv1beta1.CinderAPI)
+		return cr.Spec.ExtraCaBundleSecretNames
+	}); err != nil {
+		return err
+	}
+
+	// index transportTLSSecretField
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &cinderv1beta1.CinderAPI{}, transportTLSSecretField, func(rawObj client.Object) []string {
+		// Extract the secret name from the spec, if one is provided
+		cr := rawObj.(*cinderv1beta1.CinderAPI)
+		if cr.Spec.TransportTLSSecret == "" {
+			return nil
+		}
+		return []string{cr.Spec.TransportTLSSecret}
+	}); err != nil {
+		return err
+	}
+
+	// index glanceCASecretField
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &cinderv1beta1.CinderAPI{}, glanceCASecretField, func(rawObj client.Object) []string {
+		// Extract the secret name from the spec, if one is provided
+		cr := rawObj.(*cinderv1beta1.CinderAPI)
+		if cr.Spec.GlanceCASecret == "" {
+			return nil
+		}
+		return []string{cr.Spec.GlanceCASecret}
+	}); err != nil {
+		return err
+	}
+
+	// index hostAliasesConfigMapField
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &cinderv1beta1.CinderAPI{}, hostAliasesConfigMapField, func(rawObj client.Object) []string {
+		// Extract the configmap name from the spec, if one is provided
+		cr := rawObj.(*cinderv1beta1.CinderAPI)
+		if cr.Spec.HostAliasesConfigMap == "" {
+			return nil
+		}
+		return []string{cr.Spec.HostAliasesConfigMap}
+	}); err != nil {
+		return err
+	}
+
 	// index tlsAPIInternalField
 	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &cinderv1beta1.CinderAPI{}, tlsAPIInternalField, func(rawObj client.Object) []string {
 		// Extract the secret name from the spec, if one is provided
@@ -309,6 +404,8 @@ func (r *CinderAPIReconciler) SetupWithManager(ctx context.Context, mgr ctrl.Man
 		Owns(&keystonev1.KeystoneService{}).
 		Owns(&keystonev1.KeystoneEndpoint{}).
 		Owns(&appsv1.StatefulSet{}).
+		Owns(&policyv1.PodDisruptionBudget{}).
+		Owns(&autoscalingv2.HorizontalPodAutoscaler{}).
 		Owns(&corev1.Service{}).
 		// watch the secrets we don't own
 		Watches(&corev1.Secret{},
@@ -318,6 +415,11 @@ func (r *CinderAPIReconciler) SetupWithManager(ctx context.Context, mgr ctrl.Man
 			handler.EnqueueRequestsFromMapFunc(r.findObjectsForSrc),
 			builder.WithPredicates(predicate.ResourceVersionChangedPredicate{}),
 		).
+		Watches(
+			&corev1.ConfigMap{},
+			handler.EnqueueRequestsFromMapFunc(r.findObjectsForSrc),
+			builder.WithPredicates(predicate.ResourceVersionChangedPredicate{}),
+		).
 		Complete(r)
 }
 
@@ -359,12 +461,23 @@ func (r *CinderAPIReconciler) reconcileDelete(ctx context.Context, instance *cin
 
 	Log.Info(fmt.Sprintf("Reconciling Service '%s' delete", instance.Name))
 
-	// It's possible to get here before the endpoints have been set in the status, so check for this
-	if instance.Status.APIEndpoints != nil {
+	// It's possible to get here before the endpoints have been set in the status, so check for this.
+	// Adopted keystone services were never given our finalizer and are left as they are.
+	if instance.Status.APIEndpoints != nil && !instance.Spec.AdoptExistingKeystoneService {
+		// include services tracked in the status which might not be part of
+		// keystoneServices anymore
+		ksSvcNames := map[string]bool{}
 		for _, ksSvc := range keystoneServices {
+			ksSvcNames[ksSvc["name"]] = true
+		}
+		for name := range instance.Status.ServiceIDs {
+			ksSvcNames[name] = true
+		}
+
+		for ksSvcName := range ksSvcNames {
 
 			// Remove the finalizer from our KeystoneEndpoint CR
-			keystoneEndpoint, err := keystonev1.GetKeystoneEndpointWithName(ctx, helper, ksSvc["name"], instance.Namespace)
+			keystoneEndpoint, err := keystonev1.GetKeystoneEndpointWithName(ctx, helper, ksSvcName, instance.Namespace)
 			if err != nil && !k8s_errors.IsNotFound(err) {
 				return ctrl.Result{}, err
 			}
@@ -378,7 +491,7 @@ func (r *CinderAPIReconciler) reconcileDelete(ctx context.Context, instance *cin
 			}
 
 			// Remove the finalizer from our KeystoneService CR
-			keystoneService, err := keystonev1.GetKeystoneServiceWithName(ctx, helper, ksSvc["name"], instance.Namespace)
+			keystoneService, err := keystonev1.GetKeystoneServiceWithName(ctx, helper, ksSvcName, instance.Namespace)
 			if err != nil && !k8s_errors.IsNotFound(err) {
 				return ctrl.Result{}, err
 			}
@@ -400,27 +513,22 @@ func (r *CinderAPIReconciler) reconcileDelete(ctx context.Context, instance *cin
 	return ctrl.Result{}, nil
 }
 
-func (r *CinderAPIReconciler) reconcileInit(
+// exposeService - creates the Services (and Routes) of the cinder-api
+// endpoints and returns the V3 endpoint URLs, which are also stored in the
+// status
+func (r *CinderAPIReconciler) exposeService(
 	ctx context.Context,
 	instance *cinderv1beta1.CinderAPI,
 	helper *helper.Helper,
 	serviceLabels map[string]string,
-) (ctrl.Result, error) {
-	Log := r.GetLogger(ctx)
-
-	Log.Info(fmt.Sprintf("Reconciling Service '%s' init", instance.Name))
-
-	//
-	// expose the service (create service and return the created endpoint URLs)
-	//
-
+) (map[string]string, ctrl.Result, error) {
 	// V3
 	publicEndpointData := endpoint.Data{
-		Port: cinder.CinderPublicPort,
+		Port: cinder.GetAPIPort(instance.Spec.CinderAPITemplate, service.EndpointPublic),
 		Path: "/v3",
 	}
 	internalEndpointData := endpoint.Data{
-		Port: cinder.CinderInternalPort,
+		Port: cinder.GetAPIPort(instance.Spec.CinderAPITemplate, service.EndpointInternal),
 		Path: "/v3",
 	}
 	cinderEndpoints := map[service.Endpoint]endpoint.Data{
@@ -428,103 +536,29 @@ func (r *CinderAPIReconciler) reconcileInit(
 		service.EndpointInternal: internalEndpointData,
 	}
 
-	apiEndpointsV3 := make(map[string]string)
-
-	for endpointType, data := range cinderEndpoints {
-		endpointTypeStr := string(endpointType)
-		endpointName := cinder.ServiceName + "-" + endpointTypeStr
-		svcOverride := instance.Spec.Override.Service[endpointType]
-		if svcOverride.EmbeddedLabelsAnnotations == nil {
-			svcOverride.EmbeddedLabelsAnnotations = &service.EmbeddedLabelsAnnotations{}
-		}
-
-		exportLabels := util.MergeStringMaps(
-			serviceLabels,
-			map[string]string{
-				service.AnnotationEndpointKey: endpointTypeStr,
-			},
-		)
-
-		// Create the service
-		svc, err := service.NewService(
-			service.GenericService(&service.GenericServiceDetails{
-				Name:      endpointName,
-				Namespace: instance.Namespace,
-				Labels:    exportLabels,
-				Selector:  serviceLabels,
-				Port: service.GenericServicePort{
-					Name:     endpointName,
-					Port:     data.Port,
-					Protocol: corev1.ProtocolTCP,
-				},
-			}),
-			5,
-			&svcOverride.OverrideSpec,
-		)
-		if err != nil {
-			instance.Status.Conditions.Set(condition.FalseCondition(
-				condition.ExposeServiceReadyCondition,
-				condition.ErrorReason,
-				condition.SeverityWarning,
-				condition.ExposeServiceReadyErrorMessage,
-				err.Error()))
-
-			return ctrl.Result{}, err
-		}
-
-		svc.AddAnnotation(map[string]string{
-			service.AnnotationEndpointKey: endpointTypeStr,
-		})
-
-		// add Annotation to whether creating an ingress is required or not
-		if endpointType == service.EndpointPublic && svc.GetServiceType() == corev1.ServiceTypeClusterIP {
-			svc.AddAnnotation(map[string]string{
-				service.AnnotationIngressCreateKey: "true",
-			})
-		} else {
-			svc.AddAnnotation(map[string]string{
-				service.AnnotationIngressCreateKey: "false",
-			})
-			if svc.GetServiceType() == corev1.ServiceTypeLoadBalancer {
-				svc.AddAnnotation(map[string]string{
-					service.AnnotationHostnameKey: svc.GetServiceHostname(), // add annotation to register service name in dnsmasq
-				})
-			}
-		}
-
-		ctrlResult, err := svc.CreateOrPatch(ctx, helper)
-		if err != nil {
-			instance.Status.Conditions.Set(condition.FalseCondition(
-				condition.ExposeServiceReadyCondition,
-				condition.ErrorReason,
-				condition.SeverityWarning,
-				condition.ExposeServiceReadyErrorMessage,
-				err.Error()))
-
-			return ctrlResult, err
-		} else if (ctrlResult != ctrl.Result{}) {
-			instance.Status.Conditions.Set(condition.FalseCondition(
-				condition.ExposeServiceReadyCondition,
-				condition.RequestedReason,
-				condition.SeverityInfo,
-				condition.ExposeServiceReadyRunningMessage))
-			return ctrlResult, nil
-		}
-		// create service - end
+	// an internal only cinder-api neither gets the public Service, nor its
+	// Route and Keystone endpoint
+	if instance.Spec.ExposePublic != nil && !*instance.Spec.ExposePublic {
+		delete(cinderEndpoints, service.EndpointPublic)
+	}
 
-		// if TLS is enabled
-		if instance.Spec.TLS.API.Enabled(endpointType) {
-			// set endpoint protocol to https
-			data.Protocol = ptr.To(service.ProtocolHTTPS)
-		}
+	// delete the Services no longer exposed, e.g. the public one of an
+	// internal only cinder-api, or the ones named after a previous ServiceName
+	err := r.deleteStaleEndpointServices(ctx, instance, cinderEndpoints)
+	if err != nil {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			condition.ExposeServiceReadyCondition,
+			condition.ErrorReason,
+			condition.SeverityWarning,
+			condition.ExposeServiceReadyErrorMessage,
+			err.Error()))
+		return nil, ctrl.Result{}, err
+	}
 
-		apiEndpointsV3[string(endpointType)], err = svc.GetAPIEndpoint(
-			svcOverride.EndpointURL, data.Protocol, data.Path)
-		if err != nil {
-			return ctrl.Result{}, err
-		}
+	apiEndpointsV3, ctrlResult, err := r.exposeEndpoints(ctx, instance, helper, serviceLabels, cinderEndpoints)
+	if err != nil || (ctrlResult != ctrl.Result{}) {
+		return nil, ctrlResult, err
 	}
-	instance.Status.Conditions.MarkTrue(condition.ExposeServiceReadyCondition, condition.ExposeServiceReadyMessage)
 
 	//
 	// Update instance status with service endpoint url from route host information
@@ -537,6 +571,19 @@ func (r *CinderAPIReconciler) reconcileInit(
 
 	// expose service - end
 
+	return apiEndpointsV3, ctrl.Result{}, nil
+}
+
+func (r *CinderAPIReconciler) reconcileInit(
+	ctx context.Context,
+	instance *cinderv1beta1.CinderAPI,
+	helper *helper.Helper,
+	serviceLabels map[string]string,
+) (ctrl.Result, error) {
+	Log := r.GetLogger(ctx)
+
+	Log.Info(fmt.Sprintf("Reconciling Service '%s' init", instance.Name))
+
 	//
 	// create service and user in keystone - - https://docs.openstack.org/Cinder/latest/install/install-rdo.html#configure-user-and-endpoints
 	// TODO: rework this
@@ -545,18 +592,38 @@ func (r *CinderAPIReconciler) reconcileInit(
 		instance.Status.ServiceIDs = map[string]string{}
 	}
 
+	if instance.Spec.AdoptExistingKeystoneService {
+		ctrlResult, err := r.adoptKeystoneServices(ctx, instance, helper)
+		if err != nil || (ctrlResult != ctrl.Result{}) {
+			return ctrlResult, err
+		}
+
+		Log.Info(fmt.Sprintf("Reconciled Service '%s' init successfully", instance.Name))
+		return ctrl.Result{}, nil
+	}
+
+	ksSvcEnabled := true
+	if instance.Spec.KeystoneServiceEnabled != nil {
+		ksSvcEnabled = *instance.Spec.KeystoneServiceEnabled
+	}
+
+	keystoneTimeout := time.Duration(instance.Spec.KeystoneTimeout) * time.Second
+	if keystoneTimeout == 0 {
+		keystoneTimeout = time.Duration(10) * time.Second
+	}
+
 	for _, ksSvc := range keystoneServices {
 		ksSvcSpec := keystonev1.KeystoneServiceSpec{
 			ServiceType:        ksSvc["type"],
 			ServiceName:        ksSvc["name"],
-			ServiceDescription: ksSvc["desc"],
-			Enabled:            true,
+			ServiceDescription: keystoneServiceDescription(instance, ksSvc),
+			Enabled:            ksSvcEnabled,
 			ServiceUser:        instance.Spec.ServiceUser,
 			Secret:             instance.Spec.Secret,
 			PasswordSelector:   instance.Spec.PasswordSelectors.Service,
 		}
 
-		ksSvcObj := keystonev1.NewKeystoneService(ksSvcSpec, instance.Namespace, serviceLabels, time.Duration(10)*time.Second)
+		ksSvcObj := keystonev1.NewKeystoneService(ksSvcSpec, instance.Namespace, serviceLabels, keystoneTimeout)
 		ctrlResult, err := ksSvcObj.CreateOrPatch(ctx, helper)
 		if err != nil {
 			return ctrlResult, err
@@ -585,7 +652,7 @@ func (r *CinderAPIReconciler) reconcileInit(
 			instance.Namespace,
 			ksEndptSpec,
 			serviceLabels,
-			time.Duration(10)*time.Second)
+			keystoneTimeout)
 		ctrlResult, err = ksEndptObj.CreateOrPatch(ctx, helper)
 		if err != nil {
 			return ctrlResult, err
@@ -603,6 +670,27 @@ func (r *CinderAPIReconciler) reconcileInit(
 		}
 	}
 
+	// the keystone services are only in the catalog once keystone reported
+	// their ID, which keeps the CinderAPI from being Ready until then
+	if unregistered := unregisteredKeystoneServices(instance); len(unregistered) > 0 {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			condition.KeystoneServiceReadyCondition,
+			condition.RequestedReason,
+			condition.SeverityInfo,
+			cinderv1beta1.KeystoneServiceNotRegisteredMessage,
+			strings.Join(unregistered, ", ")))
+	}
+
+	//
+	// remove keystone services and endpoints that were registered by a previous
+	// version of the operator but are no longer part of keystoneServices, e.g.
+	// after a service name change
+	//
+	err := r.deleteStaleKeystoneServices(ctx, instance, helper)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
 	Log.Info(fmt.Sprintf("Reconciled Service '%s' init successfully", instance.Name))
 	return ctrl.Result{}, nil
 }
@@ -621,6 +709,10 @@ func (r *CinderAPIReconciler) reconcileNormal(ctx context.Context, instance *cin
 	if err != nil {
 		return ctrlResult, err
 	}
+	ctrlResult, err = r.verifyPasswordSelectors(ctx, helper, instance)
+	if err != nil {
+		return ctrlResult, err
+	}
 
 	//
 	// check for required TransportURL secret holding transport URL string
@@ -630,6 +722,43 @@ func (r *CinderAPIReconciler) reconcileNormal(ctx context.Context, instance *cin
 		return ctrlResult, err
 	}
 
+	//
+	// check for the optional RabbitMQ TLS secret
+	//
+	if instance.Spec.TransportTLSSecret != "" {
+		ctrlResult, err = r.getSecret(ctx, helper, instance, instance.Spec.TransportTLSSecret, &configVars)
+		if err != nil {
+			return ctrlResult, err
+		}
+	}
+
+	//
+	// check for the optional Glance API CA secret
+	//
+	if instance.Spec.GlanceCASecret != "" {
+		ctrlResult, err = r.getSecret(ctx, helper, instance, instance.Spec.GlanceCASecret, &configVars)
+		if err != nil {
+			return ctrlResult, err
+		}
+	}
+
+	//
+	// check for the optional hostAliases ConfigMap
+	//
+	var hostAliases []corev1.HostAlias
+	if instance.Spec.HostAliasesConfigMap != "" {
+		hostAliases, err = getHostAliases(ctx, helper, instance.Spec.HostAliasesConfigMap, instance.Namespace, &configVars)
+		if err != nil {
+			instance.Status.Conditions.Set(condition.FalseCondition(
+				condition.InputReadyCondition,
+				condition.ErrorReason,
+				condition.SeverityWarning,
+				condition.InputReadyErrorMessage,
+				err.Error()))
+			return ctrl.Result{}, err
+		}
+	}
+
 	//
 	// check for required service secrets
 	//
@@ -640,6 +769,15 @@ func (r *CinderAPIReconciler) reconcileNormal(ctx context.Context, instance *cin
 		}
 	}
 
+	// the extra CA bundles are mounted as is, so their content is hashed to
+	// restart the pods when a bundle changes
+	for _, secretName := range instance.Spec.ExtraCaBundleSecretNames {
+		ctrlResult, err = r.getSecret(ctx, helper, instance, secretName, &configVars)
+		if err != nil {
+			return ctrlResult, err
+		}
+	}
+
 	//
 	// check for required Cinder secrets that should have been created by parent Cinder CR
 	//
@@ -652,6 +790,16 @@ func (r *CinderAPIReconciler) reconcileNormal(ctx context.Context, instance *cin
 	for _, parentSecret := range parentSecrets {
 		ctrlResult, err = r.getSecret(ctx, helper, instance, parentSecret, &configVars)
 		if err != nil {
+			if (ctrlResult != ctrl.Result{}) {
+				// name the missing Secret, the parent Cinder might not be reconciled yet
+				instance.Status.Conditions.Set(condition.FalseCondition(
+					condition.InputReadyCondition,
+					cinderv1beta1.ParentInputMissingReason,
+					condition.SeverityInfo,
+					cinderv1beta1.InputReadyParentSecretMissingMessage,
+					parentSecret,
+					parentCinderName))
+			}
 			return ctrlResult, err
 		}
 	}
@@ -714,10 +862,21 @@ func (r *CinderAPIReconciler) reconcileNormal(ctx context.Context, instance *cin
 		common.ComponentSelector: cinderapi.ComponentName,
 	}
 
+	//
+	// expose the service (create service and return the created endpoint URLs)
+	// ahead of rendering the config, which advertises the public endpoint
+	//
+	apiEndpointsV3, ctrlResult, err := r.exposeService(ctx, instance, helper, serviceLabels)
+	if err != nil {
+		return ctrlResult, err
+	} else if (ctrlResult != ctrl.Result{}) {
+		return ctrlResult, nil
+	}
+
 	//
 	// create custom config for this cinder service
 	//
-	err = r.generateServiceConfigs(ctx, helper, instance, &configVars, serviceLabels)
+	err = r.generateServiceConfigs(ctx, helper, instance, &configVars, serviceLabels, apiEndpointsV3)
 	if err != nil {
 		instance.Status.Conditions.Set(condition.FalseCondition(
 			condition.ServiceConfigReadyCondition,
@@ -745,7 +904,7 @@ func (r *CinderAPIReconciler) reconcileNormal(ctx context.Context, instance *cin
 					condition.SeverityInfo,
 					condition.NetworkAttachmentsReadyWaitingMessage,
 					netAtt))
-				return ctrl.Result{RequeueAfter: time.Second * 10}, fmt.Errorf("network-attachment-definition %s not found", netAtt)
+				return ctrl.Result{RequeueAfter: cinder.GetRequeueInterval(instance.Spec.CinderTemplate, r.RequeueInterval)}, fmt.Errorf("network-attachment-definition %s not found", netAtt)
 			}
 			instance.Status.Conditions.Set(condition.FalseCondition(
 				condition.NetworkAttachmentsReadyCondition,
@@ -810,8 +969,18 @@ func (r *CinderAPIReconciler) reconcileNormal(ctx context.Context, instance *cin
 		return ctrl.Result{}, nil
 	}
 
+	if instance.GetAnnotations()[cinderv1beta1.PauseStatefulSetAnnotation] == "true" {
+		Log.Info(fmt.Sprintf("StatefulSet %s is paused, skipping its update", instance.Name))
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			condition.DeploymentReadyCondition,
+			condition.RequestedReason,
+			condition.SeverityInfo,
+			cinderv1beta1.DeploymentPausedMessage))
+		return ctrl.Result{}, nil
+	}
+
 	// Deploy a statefulset
-	ssDef, err := cinderapi.StatefulSet(instance, inputHash, serviceLabels, serviceAnnotations)
+	ssDef, err := cinderapi.StatefulSet(instance, inputHash, serviceLabels, serviceAnnotations, hostAliases, r.DefaultNodeSelector)
 	if err != nil {
 		instance.Status.Conditions.Set(condition.FalseCondition(
 			condition.DeploymentReadyCondition,
@@ -821,12 +990,9 @@ func (r *CinderAPIReconciler) reconcileNormal(ctx context.Context, instance *cin
 			err.Error()))
 		return ctrl.Result{}, err
 	}
-	ss := statefulset.NewStatefulSet(
-		ssDef,
-		time.Duration(5)*time.Second,
-	)
 
-	ctrlResult, err = ss.CreateOrPatch(ctx, helper)
+	// Withhold the rollout of changed pods if requested
+	deferred, err := rolloutDeferred(ctx, helper, instance, ssDef)
 	if err != nil {
 		instance.Status.Conditions.Set(condition.FalseCondition(
 			condition.DeploymentReadyCondition,
@@ -834,21 +1000,118 @@ func (r *CinderAPIReconciler) reconcileNormal(ctx context.Context, instance *cin
 			condition.SeverityWarning,
 			condition.DeploymentReadyErrorMessage,
 			err.Error()))
-		return ctrlResult, err
-	} else if (ctrlResult != ctrl.Result{}) {
-		instance.Status.Conditions.Set(condition.FalseCondition(
-			condition.DeploymentReadyCondition,
-			condition.RequestedReason,
-			condition.SeverityInfo,
-			condition.DeploymentReadyRunningMessage))
-		return ctrlResult, nil
+		return ctrl.Result{}, err
 	}
-	instance.Status.ReadyCount = ss.GetStatefulSet().Status.ReadyReplicas
+	if deferred {
+		Log.Info(fmt.Sprintf("Rollout of StatefulSet %s is deferred", ssDef.Name))
+		instance.Status.Conditions.Set(condition.TrueCondition(
+			cinderv1beta1.RolloutDeferredCondition,
+			cinderv1beta1.RolloutDeferredMessage))
+		// check again once the maintenance window might have opened
+		return ctrl.Result{RequeueAfter: cinder.GetRequeueInterval(instance.Spec.CinderTemplate, r.RequeueInterval)}, nil
+	}
+	instance.Status.Conditions.Remove(cinderv1beta1.RolloutDeferredCondition)
 
-	// verify if network attachment matches expectations
-	networkReady := false
-	networkAttachmentStatus := map[string][]string{}
-	if *instance.Spec.Replicas > 0 {
+	// Limit the rollout to a canary replica if requested
+	err = r.canaryRollout(ctx, instance, helper, ssDef)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// Keep enough replicas running during node drains
+	err = r.reconcilePodDisruptionBudget(ctx, instance, serviceLabels)
+	if err != nil {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			condition.DeploymentReadyCondition,
+			condition.ErrorReason,
+			condition.SeverityWarning,
+			condition.DeploymentReadyErrorMessage,
+			err.Error()))
+		return ctrl.Result{}, err
+	}
+
+	// Leave the number of replicas to the HorizontalPodAutoscaler if enabled
+	err = r.reconcileAutoscaling(ctx, instance, helper, ssDef, serviceLabels)
+	if err != nil {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			condition.DeploymentReadyCondition,
+			condition.ErrorReason,
+			condition.SeverityWarning,
+			condition.DeploymentReadyErrorMessage,
+			err.Error()))
+		return ctrl.Result{}, err
+	}
+
+	// Expose the metrics on a dedicated service
+	err = r.reconcileMetricsService(ctx, instance, serviceLabels)
+	if err != nil {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			condition.ExposeServiceReadyCondition,
+			condition.ErrorReason,
+			condition.SeverityWarning,
+			condition.ExposeServiceReadyErrorMessage,
+			err.Error()))
+		return ctrl.Result{}, err
+	}
+
+	// the generation of the StatefulSet tells whether it got created or updated
+	previousGeneration := int64(0)
+	currentSS, err := statefulset.GetStatefulSetWithName(ctx, helper, ssDef.Name, ssDef.Namespace)
+	if err == nil {
+		previousGeneration = currentSS.Generation
+	} else if !k8s_errors.IsNotFound(err) {
+		return ctrl.Result{}, err
+	}
+
+	ss := statefulset.NewStatefulSet(
+		ssDef,
+		time.Duration(5)*time.Second,
+	)
+
+	ctrlResult, err = ss.CreateOrPatch(ctx, helper)
+	if err != nil {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			condition.DeploymentReadyCondition,
+			condition.ErrorReason,
+			condition.SeverityWarning,
+			condition.DeploymentReadyErrorMessage,
+			err.Error()))
+		return ctrlResult, err
+	} else if (ctrlResult != ctrl.Result{}) {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			condition.DeploymentReadyCondition,
+			condition.RequestedReason,
+			condition.SeverityInfo,
+			condition.DeploymentReadyRunningMessage))
+		return ctrlResult, nil
+	}
+	instance.Status.ReadyCount = ss.GetStatefulSet().Status.ReadyReplicas
+
+	// report images referenced by tag and pods running another digest
+	driftCondition, err := imageDrift(ctx, helper, instance.Spec.ContainerImage, cinderv1beta1.CinderAPIContainerName, instance.Namespace, serviceLabels)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if driftCondition != nil {
+		instance.Status.Conditions.Set(driftCondition)
+	} else {
+		instance.Status.Conditions.Remove(cinderv1beta1.ImageDriftCondition)
+	}
+
+	deployedSS := ss.GetStatefulSet()
+	if previousGeneration == 0 {
+		r.Recorder.Eventf(instance, corev1.EventTypeNormal, "StatefulSetCreated",
+			"StatefulSet %s created", deployedSS.Name)
+	} else if deployedSS.Generation != previousGeneration {
+		r.Recorder.Eventf(instance, corev1.EventTypeNormal, "StatefulSetUpdated",
+			"StatefulSet %s updated to generation %d", deployedSS.Name, deployedSS.Generation)
+	}
+	rolloutRequeue := r.checkRolloutProgress(instance, &deployedSS)
+
+	// verify if network attachment matches expectations
+	networkReady := false
+	networkAttachmentStatus := map[string][]string{}
+	if *instance.Spec.Replicas > 0 {
 		networkReady, networkAttachmentStatus, err = nad.VerifyNetworkStatusFromAnnotation(
 			ctx,
 			helper,
@@ -878,13 +1141,434 @@ func (r *CinderAPIReconciler) reconcileNormal(ctx context.Context, instance *cin
 		return ctrl.Result{}, err
 	}
 
-	if instance.Status.ReadyCount > 0 {
+	desiredReplicas := currentReplicas(&deployedSS)
+	if instance.Status.ReadyCount < desiredReplicas {
+		// the StatefulSet status changes trigger a reconcile, the requeue
+		// only catches the ones missed while the status got updated
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			condition.DeploymentReadyCondition,
+			condition.RequestedReason,
+			condition.SeverityInfo,
+			cinderv1beta1.DeploymentRolloutProgressMessage,
+			instance.Status.ReadyCount,
+			desiredReplicas,
+			deployedSS.Status.UpdatedReplicas))
+		if rolloutRequeue == 0 || rolloutRequeue > rolloutProgressInterval {
+			rolloutRequeue = rolloutProgressInterval
+		}
+	} else if instance.Status.ReadyCount > 0 {
 		instance.Status.Conditions.MarkTrue(condition.DeploymentReadyCondition, condition.DeploymentReadyMessage)
 	}
 	// create StatefulSet - end
 
+	err = r.reconcileServiceMonitor(ctx, instance, serviceLabels)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
 	Log.Info(fmt.Sprintf("Reconciled Service '%s' successfully", instance.Name))
-	return ctrl.Result{}, nil
+	return ctrl.Result{RequeueAfter: rolloutRequeue}, nil
+}
+
+// exposeEndpoints - creates the Service of each endpoint type and returns
+// the URLs of the endpoints
+func (r *CinderAPIReconciler) exposeEndpoints(
+	ctx context.Context,
+	instance *cinderv1beta1.CinderAPI,
+	helper *helper.Helper,
+	serviceLabels map[string]string,
+	endpoints map[service.Endpoint]endpoint.Data,
+) (map[string]string, ctrl.Result, error) {
+	apiEndpoints := make(map[string]string)
+
+	for endpointType, data := range endpoints {
+		endpointTypeStr := string(endpointType)
+		endpointName := cinder.GetAPIServiceName(instance.Spec.CinderAPITemplate, endpointType)
+		svcOverride := instance.Spec.Override.Service[endpointType]
+		portName := endpointName
+		if name, ok := instance.Spec.PortNames[endpointType]; ok && name != "" {
+			portName = name
+		}
+		if svcOverride.EmbeddedLabelsAnnotations == nil {
+			svcOverride.EmbeddedLabelsAnnotations = &service.EmbeddedLabelsAnnotations{}
+		}
+
+		exportLabels := util.MergeStringMaps(
+			serviceLabels,
+			map[string]string{
+				service.AnnotationEndpointKey: endpointTypeStr,
+			},
+		)
+
+		// Create the service
+		svc, err := service.NewService(
+			service.GenericService(&service.GenericServiceDetails{
+				Name:      endpointName,
+				Namespace: instance.Namespace,
+				Labels:    exportLabels,
+				Selector:  serviceLabels,
+				Port: service.GenericServicePort{
+					Name:     portName,
+					Port:     data.Port,
+					Protocol: corev1.ProtocolTCP,
+				},
+			}),
+			5,
+			&svcOverride.OverrideSpec,
+		)
+		if err != nil {
+			instance.Status.Conditions.Set(condition.FalseCondition(
+				condition.ExposeServiceReadyCondition,
+				condition.ErrorReason,
+				condition.SeverityWarning,
+				condition.ExposeServiceReadyErrorMessage,
+				err.Error()))
+			instance.Status.Conditions.Set(condition.FalseCondition(
+				cinderv1beta1.CinderV3EndpointReadyCondition,
+				condition.ErrorReason,
+				condition.SeverityWarning,
+				cinderv1beta1.CinderV3EndpointReadyErrorMessage,
+				err.Error()))
+
+			return nil, ctrl.Result{}, err
+		}
+
+		svc.AddAnnotation(map[string]string{
+			service.AnnotationEndpointKey: endpointTypeStr,
+		})
+
+		// add Annotation to whether creating an ingress is required or not
+		if endpointType == service.EndpointPublic && svc.GetServiceType() == corev1.ServiceTypeClusterIP {
+			svc.AddAnnotation(map[string]string{
+				service.AnnotationIngressCreateKey: "true",
+			})
+		} else {
+			svc.AddAnnotation(map[string]string{
+				service.AnnotationIngressCreateKey: "false",
+			})
+			if svc.GetServiceType() == corev1.ServiceTypeLoadBalancer {
+				svc.AddAnnotation(map[string]string{
+					service.AnnotationHostnameKey: svc.GetServiceHostname(), // add annotation to register service name in dnsmasq
+				})
+			}
+		}
+
+		ctrlResult, err := svc.CreateOrPatch(ctx, helper)
+		if err != nil {
+			instance.Status.Conditions.Set(condition.FalseCondition(
+				condition.ExposeServiceReadyCondition,
+				condition.ErrorReason,
+				condition.SeverityWarning,
+				condition.ExposeServiceReadyErrorMessage,
+				err.Error()))
+			instance.Status.Conditions.Set(condition.FalseCondition(
+				cinderv1beta1.CinderV3EndpointReadyCondition,
+				condition.ErrorReason,
+				condition.SeverityWarning,
+				cinderv1beta1.CinderV3EndpointReadyErrorMessage,
+				err.Error()))
+
+			return nil, ctrlResult, err
+		} else if (ctrlResult != ctrl.Result{}) {
+			instance.Status.Conditions.Set(condition.FalseCondition(
+				condition.ExposeServiceReadyCondition,
+				condition.RequestedReason,
+				condition.SeverityInfo,
+				condition.ExposeServiceReadyRunningMessage))
+			instance.Status.Conditions.Set(condition.FalseCondition(
+				cinderv1beta1.CinderV3EndpointReadyCondition,
+				condition.RequestedReason,
+				condition.SeverityInfo,
+				cinderv1beta1.CinderV3EndpointReadyRunningMessage))
+			return nil, ctrlResult, nil
+		}
+		// create service - end
+
+		// if TLS is enabled
+		if instance.Spec.TLS.API.Enabled(endpointType) {
+			// set endpoint protocol to https
+			data.Protocol = ptr.To(service.ProtocolHTTPS)
+		}
+
+		apiEndpoints[string(endpointType)], err = svc.GetAPIEndpoint(
+			svcOverride.EndpointURL, data.Protocol, data.Path)
+		if err != nil {
+			instance.Status.Conditions.Set(condition.FalseCondition(
+				cinderv1beta1.CinderV3EndpointReadyCondition,
+				condition.ErrorReason,
+				condition.SeverityWarning,
+				cinderv1beta1.CinderV3EndpointReadyErrorMessage,
+				err.Error()))
+			return nil, ctrl.Result{}, err
+		}
+	}
+	instance.Status.Conditions.MarkTrue(cinderv1beta1.CinderV3EndpointReadyCondition, cinderv1beta1.CinderV3EndpointReadyMessage)
+	instance.Status.Conditions.MarkTrue(condition.ExposeServiceReadyCondition, condition.ExposeServiceReadyMessage)
+
+	return apiEndpoints, ctrl.Result{}, nil
+}
+
+// deleteStaleEndpointServices - deletes the Services of the endpoint types
+// owned by the CinderAPI which are not the ones of the exposed endpoints
+func (r *CinderAPIReconciler) deleteStaleEndpointServices(
+	ctx context.Context,
+	instance *cinderv1beta1.CinderAPI,
+	endpoints map[service.Endpoint]endpoint.Data,
+) error {
+	Log := r.GetLogger(ctx)
+
+	exposed := map[string]bool{}
+	for endpointType := range endpoints {
+		exposed[cinder.GetAPIServiceName(instance.Spec.CinderAPITemplate, endpointType)] = true
+	}
+
+	services := &corev1.ServiceList{}
+	err := r.Client.List(ctx, services,
+		client.InNamespace(instance.Namespace),
+		client.HasLabels{service.AnnotationEndpointKey})
+	if err != nil {
+		return err
+	}
+
+	for i := range services.Items {
+		svc := &services.Items[i]
+		endpointType := svc.Labels[service.AnnotationEndpointKey]
+		if endpointType != string(service.EndpointPublic) && endpointType != string(service.EndpointInternal) {
+			continue
+		}
+		if exposed[svc.Name] || !metav1.IsControlledBy(svc, instance) {
+			continue
+		}
+		if err := r.Client.Delete(ctx, svc); err != nil && !k8s_errors.IsNotFound(err) {
+			return err
+		}
+		Log.Info(fmt.Sprintf("Deleted stale Service %s", svc.Name))
+	}
+
+	return nil
+}
+
+// conditionEvents - the Normal events recorded when a condition becomes True
+var conditionEvents = []struct {
+	condition condition.Type
+	reason    string
+	message   string
+}{
+	{condition.KeystoneServiceReadyCondition, "KeystoneServiceReady", "Keystone services registered"},
+	{condition.KeystoneEndpointReadyCondition, "KeystoneEndpointsExposed", "Keystone endpoints exposed"},
+	{condition.ExposeServiceReadyCondition, "ServicesExposed", "Services of the API endpoints exposed"},
+}
+
+// recordEvents - records the events of the condition transitions of the
+// reconcile, and a Warning event when the reconcile failed
+func (r *CinderAPIReconciler) recordEvents(
+	instance *cinderv1beta1.CinderAPI,
+	savedConditions condition.Conditions,
+	reconcileErr error,
+) {
+	for _, ev := range conditionEvents {
+		if instance.Status.Conditions.IsTrue(ev.condition) && !savedConditions.IsTrue(ev.condition) {
+			r.Recorder.Event(instance, corev1.EventTypeNormal, ev.reason, ev.message)
+		}
+	}
+
+	if reconcileErr != nil {
+		r.Recorder.Eventf(instance, corev1.EventTypeWarning, "ReconcileError",
+			"Reconcile failed: %s", reconcileErr.Error())
+	}
+}
+
+// reconcilePodDisruptionBudget - creates or patches the PodDisruptionBudget of
+// the cinder-api pods, or deletes it when there is a single replica, as the
+// PodDisruptionBudget would then prevent the node from being drained.
+func (r *CinderAPIReconciler) reconcilePodDisruptionBudget(
+	ctx context.Context,
+	instance *cinderv1beta1.CinderAPI,
+	serviceLabels map[string]string,
+) error {
+	pdbDef := cinderapi.PodDisruptionBudget(instance, serviceLabels)
+	pdb := &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      pdbDef.Name,
+			Namespace: pdbDef.Namespace,
+		},
+	}
+
+	if instance.Spec.Replicas == nil || *instance.Spec.Replicas <= 1 {
+		err := r.Client.Delete(ctx, pdb)
+		if err != nil && !k8s_errors.IsNotFound(err) {
+			return err
+		}
+		return nil
+	}
+
+	_, err := controllerutil.CreateOrPatch(ctx, r.Client, pdb, func() error {
+		pdb.Labels = util.MergeStringMaps(pdb.Labels, pdbDef.Labels)
+		pdb.Spec = pdbDef.Spec
+		return controllerutil.SetControllerReference(instance, pdb, r.Scheme)
+	})
+	return err
+}
+
+// reconcileAutoscaling - creates or patches the HorizontalPodAutoscaler of the
+// cinder-api StatefulSet and keeps the number of replicas it scaled the
+// StatefulSet to, or deletes it when Autoscaling is disabled.
+func (r *CinderAPIReconciler) reconcileAutoscaling(
+	ctx context.Context,
+	instance *cinderv1beta1.CinderAPI,
+	h *helper.Helper,
+	ssDef *appsv1.StatefulSet,
+	serviceLabels map[string]string,
+) error {
+	hpa := &autoscalingv2.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      instance.Name,
+			Namespace: instance.Namespace,
+		},
+	}
+
+	if instance.Spec.Autoscaling == nil {
+		err := r.Client.Delete(ctx, hpa)
+		if err != nil && !k8s_errors.IsNotFound(err) {
+			return err
+		}
+		return nil
+	}
+
+	hpaDef := cinderapi.HorizontalPodAutoscaler(instance, serviceLabels)
+	_, err := controllerutil.CreateOrPatch(ctx, r.Client, hpa, func() error {
+		hpa.Labels = util.MergeStringMaps(hpa.Labels, hpaDef.Labels)
+		hpa.Spec = hpaDef.Spec
+		return controllerutil.SetControllerReference(instance, hpa, r.Scheme)
+	})
+	if err != nil {
+		return err
+	}
+
+	// don't fight the HorizontalPodAutoscaler over the replicas
+	current, err := statefulset.GetStatefulSetWithName(ctx, h, ssDef.Name, ssDef.Namespace)
+	if err != nil {
+		if k8s_errors.IsNotFound(err) {
+			ssDef.Spec.Replicas = hpaDef.Spec.MinReplicas
+			return nil
+		}
+		return err
+	}
+	ssDef.Spec.Replicas = current.Spec.Replicas
+
+	return nil
+}
+
+// reconcileMetricsService - creates or patches the ClusterIP service exposing
+// the cinder-api metrics and stores its endpoint in the status.
+func (r *CinderAPIReconciler) reconcileMetricsService(
+	ctx context.Context,
+	instance *cinderv1beta1.CinderAPI,
+	serviceLabels map[string]string,
+) error {
+	svcDef := cinderapi.MetricsService(instance, metricsServiceLabels(serviceLabels), serviceLabels)
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      svcDef.Name,
+			Namespace: svcDef.Namespace,
+		},
+	}
+
+	_, err := controllerutil.CreateOrPatch(ctx, r.Client, svc, func() error {
+		svc.Labels = util.MergeStringMaps(svc.Labels, svcDef.Labels)
+		svc.Spec.Type = svcDef.Spec.Type
+		svc.Spec.Selector = svcDef.Spec.Selector
+		svc.Spec.Ports = svcDef.Spec.Ports
+		return controllerutil.SetControllerReference(instance, svc, r.Scheme)
+	})
+	if err != nil {
+		return err
+	}
+
+	instance.Status.MetricsEndpoint = fmt.Sprintf("http://%s.%s.svc:%d%s",
+		svc.Name, svc.Namespace, instance.Spec.MetricsPort, cinderapi.MetricsPath)
+
+	return nil
+}
+
+// metricsServiceLabels - labels of the metrics service, which the
+// ServiceMonitor selects it on
+func metricsServiceLabels(serviceLabels map[string]string) map[string]string {
+	return util.MergeStringMaps(
+		serviceLabels,
+		map[string]string{
+			service.AnnotationEndpointKey: cinderapi.MetricsPortName,
+		},
+	)
+}
+
+// reconcileServiceMonitor - creates or patches the ServiceMonitor scraping the
+// internal cinder-api service, or deletes it when EnableServiceMonitor is
+// unset. The ServiceMonitor CRD is optional, when it is not installed the
+// ServiceMonitor is skipped and reported by the CinderAPIServiceMonitorReady
+// condition.
+func (r *CinderAPIReconciler) reconcileServiceMonitor(
+	ctx context.Context,
+	instance *cinderv1beta1.CinderAPI,
+	serviceLabels map[string]string,
+) error {
+	Log := r.GetLogger(ctx)
+
+	_, err := r.Client.RESTMapper().RESTMapping(
+		cinderapi.ServiceMonitorGVK.GroupKind(), cinderapi.ServiceMonitorGVK.Version)
+	if err != nil {
+		if !meta.IsNoMatchError(err) {
+			return err
+		}
+		if instance.Spec.EnableServiceMonitor {
+			Log.Info(fmt.Sprintf("ServiceMonitor CRD not installed, skipping the ServiceMonitor of '%s'", instance.Name))
+			instance.Status.Conditions.Set(condition.FalseCondition(
+				cinderv1beta1.CinderAPIServiceMonitorReadyCondition,
+				condition.ErrorReason,
+				condition.SeverityWarning,
+				cinderv1beta1.CinderAPIServiceMonitorReadyCRDMissingMessage))
+		} else {
+			instance.Status.Conditions.Remove(cinderv1beta1.CinderAPIServiceMonitorReadyCondition)
+		}
+		return nil
+	}
+
+	serviceMonitor := &unstructured.Unstructured{}
+	serviceMonitor.SetGroupVersionKind(cinderapi.ServiceMonitorGVK)
+	serviceMonitor.SetName(instance.Name)
+	serviceMonitor.SetNamespace(instance.Namespace)
+
+	if !instance.Spec.EnableServiceMonitor {
+		err = r.Client.Delete(ctx, serviceMonitor)
+		if err != nil && !k8s_errors.IsNotFound(err) {
+			return err
+		}
+		instance.Status.Conditions.Remove(cinderv1beta1.CinderAPIServiceMonitorReadyCondition)
+		return nil
+	}
+
+	// scrape the dedicated metrics service
+	serviceMonitorDef := cinderapi.ServiceMonitor(
+		instance, serviceLabels, metricsServiceLabels(serviceLabels), cinderapi.MetricsPortName, "http")
+	_, err = controllerutil.CreateOrPatch(ctx, r.Client, serviceMonitor, func() error {
+		serviceMonitor.SetLabels(util.MergeStringMaps(serviceMonitor.GetLabels(), serviceMonitorDef.GetLabels()))
+		serviceMonitor.Object["spec"] = serviceMonitorDef.Object["spec"]
+		return controllerutil.SetControllerReference(instance, serviceMonitor, r.Scheme)
+	})
+	if err != nil {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			cinderv1beta1.CinderAPIServiceMonitorReadyCondition,
+			condition.ErrorReason,
+			condition.SeverityWarning,
+			cinderv1beta1.CinderAPIServiceMonitorReadyErrorMessage,
+			err.Error()))
+		return err
+	}
+	instance.Status.Conditions.MarkTrue(
+		cinderv1beta1.CinderAPIServiceMonitorReadyCondition,
+		cinderv1beta1.CinderAPIServiceMonitorReadyMessage)
+
+	return nil
 }
 
 func (r *CinderAPIReconciler) reconcileUpdate(ctx context.Context, instance *cinderv1beta1.CinderAPI, helper *helper.Helper) (ctrl.Result, error) {
@@ -892,8 +1576,7 @@ func (r *CinderAPIReconciler) reconcileUpdate(ctx context.Context, instance *cin
 
 	Log.Info(fmt.Sprintf("Reconciling Service '%s' update", instance.Name))
 
-	// TODO: should have minor update tasks if required
-	// - delete dbsync hash from status to rerun it?
+	// nothing to do, config and secret changes roll the pods through CONFIG_HASH
 
 	Log.Info(fmt.Sprintf("Reconciled Service '%s' update successfully", instance.Name))
 	return ctrl.Result{}, nil
@@ -904,13 +1587,389 @@ func (r *CinderAPIReconciler) reconcileUpgrade(ctx context.Context, instance *ci
 
 	Log.Info(fmt.Sprintf("Reconciling Service '%s' upgrade", instance.Name))
 
-	// TODO: should have major version upgrade tasks
-	// -delete dbsync hash from status to rerun it?
+	// nothing to do, the db sync of the new image is run by the Cinder controller
 
 	Log.Info(fmt.Sprintf("Reconciled Service '%s' upgrade successfully", instance.Name))
 	return ctrl.Result{}, nil
 }
 
+// canaryRollout - when CanaryRollout is enabled, an update of the StatefulSet
+// is first limited to the replica with the highest ordinal using the
+// RollingUpdate partition. The remaining replicas are only updated once the
+// canary pod is Ready. If the canary fails, the rollout is halted and the
+// remaining replicas keep running with the previous configuration.
+func (r *CinderAPIReconciler) canaryRollout(
+	ctx context.Context,
+	instance *cinderv1beta1.CinderAPI,
+	h *helper.Helper,
+	ssDef *appsv1.StatefulSet,
+) error {
+	Log := r.GetLogger(ctx)
+
+	if !instance.Spec.CanaryRollout {
+		return nil
+	}
+
+	current, err := statefulset.GetStatefulSetWithName(ctx, h, ssDef.Name, ssDef.Namespace)
+	if err != nil {
+		if k8s_errors.IsNotFound(err) {
+			// initial deployment, there is nothing to protect yet
+			return nil
+		}
+		return err
+	}
+
+	replicas := currentReplicas(current)
+	if replicas < 2 {
+		return nil
+	}
+
+	canary := replicas - 1
+	partition := int32(0)
+	if current.Spec.UpdateStrategy.RollingUpdate != nil &&
+		current.Spec.UpdateStrategy.RollingUpdate.Partition != nil {
+		partition = *current.Spec.UpdateStrategy.RollingUpdate.Partition
+	}
+
+	switch {
+	case podTemplateChanged(&current.Spec.Template, &ssDef.Spec.Template):
+		// new rollout, start with the canary replica only
+		partition = canary
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			cinderv1beta1.CinderAPICanaryRolloutCondition,
+			condition.RequestedReason,
+			condition.SeverityInfo,
+			cinderv1beta1.CinderAPICanaryRolloutRunningMessage))
+		Log.Info(fmt.Sprintf("Starting canary rollout of %s", ssDef.Name))
+
+	case partition == canary && current.Status.UpdateRevision != current.Status.CurrentRevision:
+		if current.Status.ObservedGeneration == current.Generation &&
+			current.Status.UpdatedReplicas >= 1 &&
+			current.Status.ReadyReplicas == replicas {
+			// canary is healthy, roll out the remaining replicas
+			partition = 0
+			instance.Status.Conditions.MarkTrue(
+				cinderv1beta1.CinderAPICanaryRolloutCondition,
+				cinderv1beta1.CinderAPICanaryRolloutMessage)
+			Log.Info(fmt.Sprintf("Canary of %s is ready, continue rollout", ssDef.Name))
+			break
+		}
+
+		canaryPodName := fmt.Sprintf("%s-%d", current.Name, canary)
+		failed, err := canaryPodFailed(ctx, h, canaryPodName, current.Namespace)
+		if err != nil {
+			return err
+		}
+		if failed {
+			instance.Status.Conditions.Set(condition.FalseCondition(
+				cinderv1beta1.CinderAPICanaryRolloutCondition,
+				condition.ErrorReason,
+				condition.SeverityWarning,
+				cinderv1beta1.CinderAPICanaryRolloutErrorMessage,
+				canaryPodName))
+		}
+
+	case partition == canary:
+		// nothing left to roll out
+		partition = 0
+	}
+
+	// keep the other RollingUpdate settings of the UpdateStrategy
+	rollingUpdate := &appsv1.RollingUpdateStatefulSetStrategy{}
+	if ssDef.Spec.UpdateStrategy.RollingUpdate != nil {
+		rollingUpdate = ssDef.Spec.UpdateStrategy.RollingUpdate.DeepCopy()
+	}
+	rollingUpdate.Partition = &partition
+	ssDef.Spec.UpdateStrategy = appsv1.StatefulSetUpdateStrategy{
+		Type:          appsv1.RollingUpdateStatefulSetStrategyType,
+		RollingUpdate: rollingUpdate,
+	}
+
+	return nil
+}
+
+// rolloutProgressInterval - how often the progress of an incomplete rollout
+// gets reported in the DeploymentReady condition
+const rolloutProgressInterval = time.Duration(10) * time.Second
+
+// currentReplicas - returns the replicas of the deployed StatefulSet, which
+// the HorizontalPodAutoscaler may have scaled away from the Replicas of the
+// spec. Unset replicas default to 1, like the StatefulSet API does.
+func currentReplicas(current *appsv1.StatefulSet) int32 {
+	if current.Spec.Replicas == nil {
+		return 1
+	}
+	return *current.Spec.Replicas
+}
+
+// checkRolloutProgress - tracks when the rollout of the StatefulSet started and
+// sets the RolloutStuck condition once it did not complete within the progress
+// deadline. Returns when to check the progress again while the rollout runs.
+func (r *CinderAPIReconciler) checkRolloutProgress(
+	instance *cinderv1beta1.CinderAPI,
+	ss *appsv1.StatefulSet,
+) time.Duration {
+	replicas := currentReplicas(ss)
+
+	rolling := ss.Status.ObservedGeneration < ss.Generation ||
+		ss.Status.UpdateRevision != ss.Status.CurrentRevision ||
+		ss.Status.UpdatedReplicas < replicas ||
+		ss.Status.ReadyReplicas < replicas
+	if !rolling {
+		instance.Status.RolloutStartTime = nil
+		instance.Status.Conditions.Remove(cinderv1beta1.RolloutStuckCondition)
+		return 0
+	}
+
+	if instance.Status.RolloutStartTime == nil {
+		now := metav1.Now()
+		instance.Status.RolloutStartTime = &now
+	}
+
+	deadline := time.Duration(instance.Spec.ProgressDeadlineSeconds) * time.Second
+	remaining := deadline - time.Since(instance.Status.RolloutStartTime.Time)
+	if remaining > 0 {
+		return remaining
+	}
+
+	instance.Status.Conditions.Set(&condition.Condition{
+		Type:     cinderv1beta1.RolloutStuckCondition,
+		Status:   corev1.ConditionTrue,
+		Reason:   condition.Reason("ProgressDeadlineExceeded"),
+		Severity: condition.SeverityWarning,
+		Message:  fmt.Sprintf(cinderv1beta1.RolloutStuckMessage, ss.Name, instance.Spec.ProgressDeadlineSeconds),
+	})
+	return 0
+}
+
+// statusSummary - returns the message of the most severe condition which is
+// not True, or the count of ready replicas when there is none
+func statusSummary(conditions condition.Conditions, readyCount int32, replicas int32) string {
+	if c := conditions.Mirror(condition.ReadyCondition); c != nil && c.Status != corev1.ConditionTrue {
+		return c.Message
+	}
+
+	return fmt.Sprintf("%d/%d ready", readyCount, replicas)
+}
+
+// podTemplateChanged - returns true if the images or the CONFIG_HASH of the
+// containers differ between the two pod templates
+func podTemplateChanged(current *corev1.PodTemplateSpec, desired *corev1.PodTemplateSpec) bool {
+	if len(current.Spec.Containers) != len(desired.Spec.Containers) {
+		return true
+	}
+	for i, c := range desired.Spec.Containers {
+		if current.Spec.Containers[i].Image != c.Image {
+			return true
+		}
+		if configHashOf(current.Spec.Containers[i].Env) != configHashOf(c.Env) {
+			return true
+		}
+	}
+	return false
+}
+
+// configHashOf - returns the value of the CONFIG_HASH env var
+func configHashOf(envs []corev1.EnvVar) string {
+	for _, e := range envs {
+		if e.Name == "CONFIG_HASH" {
+			return e.Value
+		}
+	}
+	return ""
+}
+
+// canaryPodFailed - returns true if a container of the canary pod got
+// restarted or is crash looping
+func canaryPodFailed(ctx context.Context, h *helper.Helper, name string, namespace string) (bool, error) {
+	pod := &corev1.Pod{}
+	err := h.GetClient().Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, pod)
+	if err != nil {
+		if k8s_errors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	for _, cs := range pod.Status.ContainerStatuses {
+		if cs.RestartCount > 0 ||
+			(cs.State.Waiting != nil && cs.State.Waiting.Reason == "CrashLoopBackOff") {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// keystoneServiceDescription - returns the description the keystone service
+// gets registered with, the override of the spec or the default description
+// with the optional suffix
+func keystoneServiceDescription(instance *cinderv1beta1.CinderAPI, ksSvc map[string]string) string {
+	if desc := instance.Spec.KeystoneServiceDescriptions[ksSvc["name"]]; desc != "" {
+		return desc
+	}
+	if instance.Spec.KeystoneServiceDescriptionSuffix != "" {
+		return fmt.Sprintf("%s - %s", ksSvc["desc"], instance.Spec.KeystoneServiceDescriptionSuffix)
+	}
+	return ksSvc["desc"]
+}
+
+// plannedChanges - returns a summary of the StatefulSet, Services and keystone
+// registrations reconcileNormal would create or patch
+func plannedChanges(instance *cinderv1beta1.CinderAPI) []string {
+	replicas := int32(1)
+	if instance.Spec.Replicas != nil {
+		replicas = *instance.Spec.Replicas
+	}
+	changes := []string{
+		fmt.Sprintf("StatefulSet %s with %d replicas of %s", instance.Name, replicas, instance.Spec.ContainerImage),
+	}
+
+	endpoints := []service.Endpoint{service.EndpointPublic, service.EndpointInternal}
+	if instance.Spec.ExposePublic != nil && !*instance.Spec.ExposePublic {
+		endpoints = []service.Endpoint{service.EndpointInternal}
+	}
+	for _, endpt := range endpoints {
+		changes = append(changes, fmt.Sprintf("Service %s on port %d",
+			cinder.GetAPIServiceName(instance.Spec.CinderAPITemplate, endpt),
+			cinder.GetAPIPort(instance.Spec.CinderAPITemplate, endpt)))
+	}
+
+	if instance.Spec.AdoptExistingKeystoneService {
+		return changes
+	}
+	for _, ksSvc := range keystoneServices {
+		changes = append(changes,
+			fmt.Sprintf("KeystoneService %s (%s)", ksSvc["name"], keystoneServiceDescription(instance, ksSvc)),
+			fmt.Sprintf("KeystoneEndpoint %s", ksSvc["name"]))
+	}
+
+	return changes
+}
+
+// adoptKeystoneServices - populate the ServiceIDs in the status from the
+// KeystoneService CRs registered out-of-band, without creating or patching
+// them or their KeystoneEndpoint CRs
+func (r *CinderAPIReconciler) adoptKeystoneServices(
+	ctx context.Context,
+	instance *cinderv1beta1.CinderAPI,
+	h *helper.Helper,
+) (ctrl.Result, error) {
+	for _, ksSvc := range keystoneServices {
+		ksSvcObj, err := keystonev1.GetKeystoneServiceWithName(ctx, h, ksSvc["name"], instance.Namespace)
+		if err != nil {
+			if k8s_errors.IsNotFound(err) {
+				instance.Status.Conditions.Set(condition.FalseCondition(
+					condition.KeystoneServiceReadyCondition,
+					condition.RequestedReason,
+					condition.SeverityInfo,
+					cinderv1beta1.KeystoneServiceAdoptWaitingMessage,
+					ksSvc["name"]))
+				return ctrl.Result{RequeueAfter: cinder.GetRequeueInterval(instance.Spec.CinderTemplate, r.RequeueInterval)}, nil
+			}
+			return ctrl.Result{}, err
+		}
+
+		instance.Status.ServiceIDs[ksSvc["name"]] = ksSvcObj.Status.ServiceID
+	}
+
+	// the adopted services are not owned, so there is no watch triggering a
+	// reconcile once keystone reports their ID
+	if unregistered := unregisteredKeystoneServices(instance); len(unregistered) > 0 {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			condition.KeystoneServiceReadyCondition,
+			condition.RequestedReason,
+			condition.SeverityInfo,
+			cinderv1beta1.KeystoneServiceNotRegisteredMessage,
+			strings.Join(unregistered, ", ")))
+		return ctrl.Result{RequeueAfter: cinder.GetRequeueInterval(instance.Spec.CinderTemplate, r.RequeueInterval)}, nil
+	}
+
+	instance.Status.Conditions.MarkTrue(condition.KeystoneServiceReadyCondition, cinderv1beta1.KeystoneServiceAdoptedMessage)
+	instance.Status.Conditions.MarkTrue(condition.KeystoneEndpointReadyCondition, cinderv1beta1.KeystoneServiceAdoptedMessage)
+
+	return ctrl.Result{}, nil
+}
+
+// unregisteredKeystoneServices - returns the names of the keystoneServices
+// without a ServiceID in the status
+func unregisteredKeystoneServices(instance *cinderv1beta1.CinderAPI) []string {
+	unregistered := []string{}
+	for _, ksSvc := range keystoneServices {
+		if instance.Status.ServiceIDs[ksSvc["name"]] == "" {
+			unregistered = append(unregistered, ksSvc["name"])
+		}
+	}
+	return unregistered
+}
+
+// deleteStaleKeystoneServices - delete the KeystoneEndpoint and KeystoneService
+// CRs tracked in the status which are not in keystoneServices anymore
+func (r *CinderAPIReconciler) deleteStaleKeystoneServices(
+	ctx context.Context,
+	instance *cinderv1beta1.CinderAPI,
+	h *helper.Helper,
+) error {
+	Log := r.GetLogger(ctx)
+
+	current := map[string]bool{}
+	for _, ksSvc := range keystoneServices {
+		current[ksSvc["name"]] = true
+	}
+
+	for name := range instance.Status.ServiceIDs {
+		if current[name] {
+			continue
+		}
+
+		err := keystonev1.DeleteKeystoneEndpointWithName(ctx, h, name, instance.Namespace)
+		if err != nil {
+			return err
+		}
+
+		err = keystonev1.DeleteKeystoneServiceWithName(ctx, h, name, instance.Namespace)
+		if err != nil {
+			return err
+		}
+
+		delete(instance.Status.ServiceIDs, name)
+		delete(instance.Status.APIEndpoints, name)
+		Log.Info(fmt.Sprintf("Deleted stale keystone service %s", name))
+	}
+
+	return nil
+}
+
+// verifyPasswordSelectors - check the keys selected by the PasswordSelectors exist in the
+// OpenStack secret, a typo would otherwise register the Keystone service with an empty password
+func (r *CinderAPIReconciler) verifyPasswordSelectors(
+	ctx context.Context,
+	h *helper.Helper,
+	instance *cinderv1beta1.CinderAPI,
+) (ctrl.Result, error) {
+	ospSecret, _, err := secret.GetSecret(ctx, h, instance.Spec.Secret, instance.Namespace)
+	if err != nil {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			condition.InputReadyCondition,
+			condition.ErrorReason,
+			condition.SeverityWarning,
+			condition.InputReadyErrorMessage,
+			err.Error()))
+		return ctrl.Result{}, err
+	}
+
+	if key := cinder.GetMissingSecretKey(ospSecret, instance.Spec.PasswordSelectors.Service); key != "" {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			condition.InputReadyCondition,
+			cinderv1beta1.SecretKeyMissingReason,
+			condition.SeverityWarning,
+			cinderv1beta1.InputReadySecretKeyMissingMessage,
+			key,
+			instance.Spec.Secret))
+		return ctrl.Result{RequeueAfter: cinder.GetRequeueInterval(instance.Spec.CinderTemplate, r.RequeueInterval)}, fmt.Errorf("key %s not found in Secret %s", key, instance.Spec.Secret)
+	}
+
+	return ctrl.Result{}, nil
+}
+
 // getSecret - get the specified secret, and add its hash to envVars
 func (r *CinderAPIReconciler) getSecret(
 	ctx context.Context,
@@ -924,10 +1983,11 @@ func (r *CinderAPIReconciler) getSecret(
 		if k8s_errors.IsNotFound(err) {
 			instance.Status.Conditions.Set(condition.FalseCondition(
 				condition.InputReadyCondition,
-				condition.RequestedReason,
+				cinderv1beta1.SecretMissingReason,
 				condition.SeverityInfo,
-				condition.InputReadyWaitingMessage))
-			return ctrl.Result{RequeueAfter: time.Duration(10) * time.Second}, fmt.Errorf("Secret %s not found", secretName)
+				cinderv1beta1.InputReadySecretMissingMessage,
+				secretName))
+			return ctrl.Result{RequeueAfter: cinder.GetRequeueInterval(instance.Spec.CinderTemplate, r.RequeueInterval)}, fmt.Errorf("Secret %s not found", secretName)
 		}
 		instance.Status.Conditions.Set(condition.FalseCondition(
 			condition.InputReadyCondition,
@@ -952,6 +2012,7 @@ func (r *CinderAPIReconciler) generateServiceConfigs(
 	instance *cinderv1beta1.CinderAPI,
 	envVars *map[string]env.Setter,
 	serviceLabels map[string]string,
+	apiEndpoints map[string]string,
 ) error {
 	//
 	// create custom Secret for cinder service-specific config input
@@ -987,7 +2048,17 @@ func (r *CinderAPIReconciler) generateServiceConfigs(
 	customData[cinder.CustomServiceConfigSecretsFileName] = customSecrets
 
 	templateParameters := map[string]interface{}{
-		"LogFile": cinderapi.LogFile,
+		"LogFile": cinderapi.GetLogFile(instance),
+	}
+
+	// advertise the public endpoint (e.g. the Route host) in the links of the
+	// API responses instead of the URL the pods are reached at
+	if publicEndpoint, ok := apiEndpoints[string(service.EndpointPublic)]; ok {
+		publicURL, err := url.Parse(publicEndpoint)
+		if err != nil {
+			return err
+		}
+		templateParameters["PublicEndpoint"] = fmt.Sprintf("%s://%s", publicURL.Scheme, publicURL.Host)
 	}
 
 	configTemplates := []util.Template{
@@ -1023,9 +2094,17 @@ func (r *CinderAPIReconciler) createHashOfInputHashes(
 	if err != nil {
 		return hash, changed, err
 	}
+	previousHash := instance.Status.Hash[common.InputHashName]
 	if hashMap, changed = util.SetHash(instance.Status.Hash, common.InputHashName, hash); changed {
 		instance.Status.Hash = hashMap
 		Log.Info(fmt.Sprintf("Input maps hash %s - %s", common.InputHashName, hash))
+		// an initial hash doesn't roll out existing pods
+		if previousHash != "" {
+			r.Recorder.Eventf(instance, corev1.EventTypeNormal, "InputHashChanged",
+				"Input hash changed from %s to %s, rolling out the pods. Changed inputs: %s",
+				previousHash, hash, strings.Join(changedInputs(instance.Status.Hash, mergedMapVars), ", "))
+		}
 	}
+	instance.Status.Hash = setInputHashes(instance.Status.Hash, mergedMapVars)
 	return hash, changed, nil
 }
@@ -19,6 +19,7 @@ package controllers
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
@@ -28,6 +29,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -71,8 +73,12 @@ func (r *CinderSchedulerReconciler) GetScheme() *runtime.Scheme {
 // CinderSchedulerReconciler reconciles a Cinder object
 type CinderSchedulerReconciler struct {
 	client.Client
-	Kclient kubernetes.Interface
-	Scheme  *runtime.Scheme
+	Kclient             kubernetes.Interface
+	Scheme              *runtime.Scheme
+	Recorder            record.EventRecorder
+	ReconcileTimeout    time.Duration
+	RequeueInterval     time.Duration
+	DefaultNodeSelector map[string]string
 }
 
 // GetLogger returns a logger object with a logging prefix of "controller.name" and additional controller context fields
@@ -85,6 +91,8 @@ func (r *CinderSchedulerReconciler) GetLogger(ctx context.Context) logr.Logger {
 //+kubebuilder:rbac:groups=cinder.openstack.org,resources=cinderschedulers/finalizers,verbs=update
 // +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;
 // +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
+// +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch
 // +kubebuilder:rbac:groups=apps,resources=statefulsets,verbs=get;list;create;update;patch;delete;watch
 // +kubebuilder:rbac:groups=k8s.cni.cncf.io,resources=network-attachment-definitions,verbs=get;list;watch
 
@@ -162,13 +170,18 @@ func (r *CinderSchedulerReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 		instance.Status.NetworkAttachments = map[string][]string{}
 	}
 
+	// Bound the reconcile steps, the instance is still patched with the
+	// original context when the deadline is exceeded
+	reconcileCtx, cancel := withReconcileTimeout(ctx, r.ReconcileTimeout)
+	defer cancel()
+
 	// Handle service delete
 	if !instance.DeletionTimestamp.IsZero() {
-		return r.reconcileDelete(ctx, instance, helper)
+		return r.reconcileDelete(reconcileCtx, instance, helper)
 	}
 
 	// Handle non-deleted clusters
-	return r.reconcileNormal(ctx, instance, helper)
+	return r.reconcileNormal(reconcileCtx, instance, helper)
 }
 
 // SetupWithManager sets up the controller with the Manager.
@@ -254,6 +267,42 @@ func (r *CinderSchedulerReconciler) SetupWithManager(ctx context.Context, mgr ct
 		return err
 	}
 
+	// index transportTLSSecretField
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &cinderv1beta1.CinderScheduler{}, transportTLSSecretField, func(rawObj client.Object) []string {
+		// Extract the secret name from the spec, if one is provided
+		cr := rawObj.(*cinderv1beta1.CinderScheduler)
+		if cr.Spec.TransportTLSSecret == "" {
+			return nil
+		}
+		return []string{cr.Spec.TransportTLSSecret}
+	}); err != nil {
+		return err
+	}
+
+	// index glanceCASecretField
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &cinderv1beta1.CinderScheduler{}, glanceCASecretField, func(rawObj client.Object) []string {
+		// Extract the secret name from the spec, if one is provided
+		cr := rawObj.(*cinderv1beta1.CinderScheduler)
+		if cr.Spec.GlanceCASecret == "" {
+			return nil
+		}
+		return []string{cr.Spec.GlanceCASecret}
+	}); err != nil {
+		return err
+	}
+
+	// index hostAliasesConfigMapField
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &cinderv1beta1.CinderScheduler{}, hostAliasesConfigMapField, func(rawObj client.Object) []string {
+		// Extract the configmap name from the spec, if one is provided
+		cr := rawObj.(*cinderv1beta1.CinderScheduler)
+		if cr.Spec.HostAliasesConfigMap == "" {
+			return nil
+		}
+		return []string{cr.Spec.HostAliasesConfigMap}
+	}); err != nil {
+		return err
+	}
+
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&cinderv1beta1.CinderScheduler{}).
 		Owns(&appsv1.StatefulSet{}).
@@ -265,6 +314,11 @@ func (r *CinderSchedulerReconciler) SetupWithManager(ctx context.Context, mgr ct
 			handler.EnqueueRequestsFromMapFunc(r.findObjectsForSrc),
 			builder.WithPredicates(predicate.ResourceVersionChangedPredicate{}),
 		).
+		Watches(
+			&corev1.ConfigMap{},
+			handler.EnqueueRequestsFromMapFunc(r.findObjectsForSrc),
+			builder.WithPredicates(predicate.ResourceVersionChangedPredicate{}),
+		).
 		Complete(r)
 }
 
@@ -349,6 +403,43 @@ func (r *CinderSchedulerReconciler) reconcileNormal(ctx context.Context, instanc
 		return ctrlResult, err
 	}
 
+	//
+	// check for the optional RabbitMQ TLS secret
+	//
+	if instance.Spec.TransportTLSSecret != "" {
+		ctrlResult, err = r.getSecret(ctx, helper, instance, instance.Spec.TransportTLSSecret, &configVars)
+		if err != nil {
+			return ctrlResult, err
+		}
+	}
+
+	//
+	// check for the optional Glance API CA secret
+	//
+	if instance.Spec.GlanceCASecret != "" {
+		ctrlResult, err = r.getSecret(ctx, helper, instance, instance.Spec.GlanceCASecret, &configVars)
+		if err != nil {
+			return ctrlResult, err
+		}
+	}
+
+	//
+	// check for the optional hostAliases ConfigMap
+	//
+	var hostAliases []corev1.HostAlias
+	if instance.Spec.HostAliasesConfigMap != "" {
+		hostAliases, err = getHostAliases(ctx, helper, instance.Spec.HostAliasesConfigMap, instance.Namespace, &configVars)
+		if err != nil {
+			instance.Status.Conditions.Set(condition.FalseCondition(
+				condition.InputReadyCondition,
+				condition.ErrorReason,
+				condition.SeverityWarning,
+				condition.InputReadyErrorMessage,
+				err.Error()))
+			return ctrl.Result{}, err
+		}
+	}
+
 	//
 	// check for required service secrets
 	//
@@ -466,7 +557,7 @@ func (r *CinderSchedulerReconciler) reconcileNormal(ctx context.Context, instanc
 					condition.SeverityInfo,
 					condition.NetworkAttachmentsReadyWaitingMessage,
 					netAtt))
-				return ctrl.Result{RequeueAfter: time.Second * 10}, fmt.Errorf("network-attachment-definition %s not found", netAtt)
+				return ctrl.Result{RequeueAfter: cinder.GetRequeueInterval(instance.Spec.CinderTemplate, r.RequeueInterval)}, fmt.Errorf("network-attachment-definition %s not found", netAtt)
 			}
 			instance.Status.Conditions.Set(condition.FalseCondition(
 				condition.NetworkAttachmentsReadyCondition,
@@ -512,8 +603,39 @@ func (r *CinderSchedulerReconciler) reconcileNormal(ctx context.Context, instanc
 	// normal reconcile tasks
 	//
 
+	if instance.GetAnnotations()[cinderv1beta1.PauseStatefulSetAnnotation] == "true" {
+		Log.Info(fmt.Sprintf("StatefulSet %s is paused, skipping its update", instance.Name))
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			condition.DeploymentReadyCondition,
+			condition.RequestedReason,
+			condition.SeverityInfo,
+			cinderv1beta1.DeploymentPausedMessage))
+		return ctrl.Result{}, nil
+	}
+
 	// Deploy a statefulset
-	ssDef := cinderscheduler.StatefulSet(instance, inputHash, serviceLabels, serviceAnnotations)
+	ssDef := cinderscheduler.StatefulSet(instance, inputHash, serviceLabels, serviceAnnotations, hostAliases, r.DefaultNodeSelector)
+
+	// Withhold the rollout of changed pods if requested
+	deferred, err := rolloutDeferred(ctx, helper, instance, ssDef)
+	if err != nil {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			condition.DeploymentReadyCondition,
+			condition.ErrorReason,
+			condition.SeverityWarning,
+			condition.DeploymentReadyErrorMessage,
+			err.Error()))
+		return ctrl.Result{}, err
+	}
+	if deferred {
+		Log.Info(fmt.Sprintf("Rollout of StatefulSet %s is deferred", ssDef.Name))
+		instance.Status.Conditions.Set(condition.TrueCondition(
+			cinderv1beta1.RolloutDeferredCondition,
+			cinderv1beta1.RolloutDeferredMessage))
+		// check again once the maintenance window might have opened
+		return ctrl.Result{RequeueAfter: cinder.GetRequeueInterval(instance.Spec.CinderTemplate, r.RequeueInterval)}, nil
+	}
+	instance.Status.Conditions.Remove(cinderv1beta1.RolloutDeferredCondition)
 	ss := statefulset.NewStatefulSet(
 		ssDef,
 		time.Duration(5)*time.Second,
@@ -538,6 +660,17 @@ func (r *CinderSchedulerReconciler) reconcileNormal(ctx context.Context, instanc
 	}
 	instance.Status.ReadyCount = ss.GetStatefulSet().Status.ReadyReplicas
 
+	// report images referenced by tag and pods running another digest
+	driftCondition, err := imageDrift(ctx, helper, instance.Spec.ContainerImage, cinderscheduler.ComponentName, instance.Namespace, serviceLabels)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if driftCondition != nil {
+		instance.Status.Conditions.Set(driftCondition)
+	} else {
+		instance.Status.Conditions.Remove(cinderv1beta1.ImageDriftCondition)
+	}
+
 	// verify if network attachment matches expectations
 	networkReady := false
 	networkAttachmentStatus := map[string][]string{}
@@ -617,10 +750,11 @@ func (r *CinderSchedulerReconciler) getSecret(
 		if k8s_errors.IsNotFound(err) {
 			instance.Status.Conditions.Set(condition.FalseCondition(
 				condition.InputReadyCondition,
-				condition.RequestedReason,
+				cinderv1beta1.SecretMissingReason,
 				condition.SeverityInfo,
-				condition.InputReadyWaitingMessage))
-			return ctrl.Result{RequeueAfter: time.Duration(10) * time.Second}, fmt.Errorf("Secret %s not found", secretName)
+				cinderv1beta1.InputReadySecretMissingMessage,
+				secretName))
+			return ctrl.Result{RequeueAfter: cinder.GetRequeueInterval(instance.Spec.CinderTemplate, r.RequeueInterval)}, fmt.Errorf("Secret %s not found", secretName)
 		}
 		instance.Status.Conditions.Set(condition.FalseCondition(
 			condition.InputReadyCondition,
@@ -710,9 +844,17 @@ func (r *CinderSchedulerReconciler) createHashOfInputHashes(
 	if err != nil {
 		return hash, changed, err
 	}
+	previousHash := instance.Status.Hash[common.InputHashName]
 	if hashMap, changed = util.SetHash(instance.Status.Hash, common.InputHashName, hash); changed {
 		instance.Status.Hash = hashMap
 		Log.Info(fmt.Sprintf("Input maps hash %s - %s", common.InputHashName, hash))
+		// an initial hash doesn't roll out existing pods
+		if previousHash != "" {
+			r.Recorder.Eventf(instance, corev1.EventTypeNormal, "InputHashChanged",
+				"Input hash changed from %s to %s, rolling out the pods. Changed inputs: %s",
+				previousHash, hash, strings.Join(changedInputs(instance.Status.Hash, mergedMapVars), ", "))
+		}
 	}
+	instance.Status.Hash = setInputHashes(instance.Status.Hash, mergedMapVars)
 	return hash, changed, nil
 }
@@ -19,6 +19,7 @@ package controllers
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
@@ -29,6 +30,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -72,8 +74,12 @@ func (r *CinderVolumeReconciler) GetScheme() *runtime.Scheme {
 // CinderVolumeReconciler reconciles a Cinder object
 type CinderVolumeReconciler struct {
 	client.Client
-	Kclient kubernetes.Interface
-	Scheme  *runtime.Scheme
+	Kclient             kubernetes.Interface
+	Scheme              *runtime.Scheme
+	Recorder            record.EventRecorder
+	ReconcileTimeout    time.Duration
+	RequeueInterval     time.Duration
+	DefaultNodeSelector map[string]string
 }
 
 // GetLogger returns a logger object with a logging prefix of "controller.name" and additional controller context fields
@@ -86,6 +92,8 @@ func (r *CinderVolumeReconciler) GetLogger(ctx context.Context) logr.Logger {
 //+kubebuilder:rbac:groups=cinder.openstack.org,resources=cindervolumes/finalizers,verbs=update
 // +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;
 // +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
+// +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch
 // +kubebuilder:rbac:groups=apps,resources=statefulsets,verbs=get;list;create;update;patch;delete;watch
 // +kubebuilder:rbac:groups=security.openshift.io,namespace=openstack,resources=securitycontextconstraints,resourceNames=privileged,verbs=use
 // +kubebuilder:rbac:groups=k8s.cni.cncf.io,resources=network-attachment-definitions,verbs=get;list;watch
@@ -164,13 +172,18 @@ func (r *CinderVolumeReconciler) Reconcile(ctx context.Context, req ctrl.Request
 		instance.Status.NetworkAttachments = map[string][]string{}
 	}
 
+	// Bound the reconcile steps, the instance is still patched with the
+	// original context when the deadline is exceeded
+	reconcileCtx, cancel := withReconcileTimeout(ctx, r.ReconcileTimeout)
+	defer cancel()
+
 	// Handle service delete
 	if !instance.DeletionTimestamp.IsZero() {
-		return r.reconcileDelete(ctx, instance, helper)
+		return r.reconcileDelete(reconcileCtx, instance, helper)
 	}
 
 	// Handle non-deleted clusters
-	return r.reconcileNormal(ctx, instance, helper)
+	return r.reconcileNormal(reconcileCtx, instance, helper)
 }
 
 // SetupWithManager sets up the controller with the Manager.
@@ -256,6 +269,42 @@ func (r *CinderVolumeReconciler) SetupWithManager(ctx context.Context, mgr ctrl.
 		return err
 	}
 
+	// index transportTLSSecretField
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &cinderv1beta1.CinderVolume{}, transportTLSSecretField, func(rawObj client.Object) []string {
+		// Extract the secret name from the spec, if one is provided
+		cr := rawObj.(*cinderv1beta1.CinderVolume)
+		if cr.Spec.TransportTLSSecret == "" {
+			return nil
+		}
+		return []string{cr.Spec.TransportTLSSecret}
+	}); err != nil {
+		return err
+	}
+
+	// index glanceCASecretField
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &cinderv1beta1.CinderVolume{}, glanceCASecretField, func(rawObj client.Object) []string {
+		// Extract the secret name from the spec, if one is provided
+		cr := rawObj.(*cinderv1beta1.CinderVolume)
+		if cr.Spec.GlanceCASecret == "" {
+			return nil
+		}
+		return []string{cr.Spec.GlanceCASecret}
+	}); err != nil {
+		return err
+	}
+
+	// index hostAliasesConfigMapField
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &cinderv1beta1.CinderVolume{}, hostAliasesConfigMapField, func(rawObj client.Object) []string {
+		// Extract the configmap name from the spec, if one is provided
+		cr := rawObj.(*cinderv1beta1.CinderVolume)
+		if cr.Spec.HostAliasesConfigMap == "" {
+			return nil
+		}
+		return []string{cr.Spec.HostAliasesConfigMap}
+	}); err != nil {
+		return err
+	}
+
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&cinderv1beta1.CinderVolume{}).
 		Owns(&appsv1.StatefulSet{}).
@@ -267,6 +316,11 @@ func (r *CinderVolumeReconciler) SetupWithManager(ctx context.Context, mgr ctrl.
 			handler.EnqueueRequestsFromMapFunc(r.findObjectsForSrc),
 			builder.WithPredicates(predicate.ResourceVersionChangedPredicate{}),
 		).
+		Watches(
+			&corev1.ConfigMap{},
+			handler.EnqueueRequestsFromMapFunc(r.findObjectsForSrc),
+			builder.WithPredicates(predicate.ResourceVersionChangedPredicate{}),
+		).
 		Complete(r)
 }
 
@@ -351,6 +405,43 @@ func (r *CinderVolumeReconciler) reconcileNormal(ctx context.Context, instance *
 		return ctrlResult, err
 	}
 
+	//
+	// check for the optional RabbitMQ TLS secret
+	//
+	if instance.Spec.TransportTLSSecret != "" {
+		ctrlResult, err = r.getSecret(ctx, helper, instance, instance.Spec.TransportTLSSecret, &configVars)
+		if err != nil {
+			return ctrlResult, err
+		}
+	}
+
+	//
+	// check for the optional Glance API CA secret
+	//
+	if instance.Spec.GlanceCASecret != "" {
+		ctrlResult, err = r.getSecret(ctx, helper, instance, instance.Spec.GlanceCASecret, &configVars)
+		if err != nil {
+			return ctrlResult, err
+		}
+	}
+
+	//
+	// check for the optional hostAliases ConfigMap
+	//
+	var hostAliases []corev1.HostAlias
+	if instance.Spec.HostAliasesConfigMap != "" {
+		hostAliases, err = getHostAliases(ctx, helper, instance.Spec.HostAliasesConfigMap, instance.Namespace, &configVars)
+		if err != nil {
+			instance.Status.Conditions.Set(condition.FalseCondition(
+				condition.InputReadyCondition,
+				condition.ErrorReason,
+				condition.SeverityWarning,
+				condition.InputReadyErrorMessage,
+				err.Error()))
+			return ctrl.Result{}, err
+		}
+	}
+
 	//
 	// check for required service secrets
 	//
@@ -469,7 +560,7 @@ func (r *CinderVolumeReconciler) reconcileNormal(ctx context.Context, instance *
 					condition.SeverityInfo,
 					condition.NetworkAttachmentsReadyWaitingMessage,
 					netAtt))
-				return ctrl.Result{RequeueAfter: time.Second * 10}, fmt.Errorf("network-attachment-definition %s not found", netAtt)
+				return ctrl.Result{RequeueAfter: cinder.GetRequeueInterval(instance.Spec.CinderTemplate, r.RequeueInterval)}, fmt.Errorf("network-attachment-definition %s not found", netAtt)
 			}
 			instance.Status.Conditions.Set(condition.FalseCondition(
 				condition.NetworkAttachmentsReadyCondition,
@@ -515,8 +606,39 @@ func (r *CinderVolumeReconciler) reconcileNormal(ctx context.Context, instance *
 	// normal reconcile tasks
 	//
 
+	if instance.GetAnnotations()[cinderv1beta1.PauseStatefulSetAnnotation] == "true" {
+		Log.Info(fmt.Sprintf("StatefulSet %s is paused, skipping its update", instance.Name))
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			condition.DeploymentReadyCondition,
+			condition.RequestedReason,
+			condition.SeverityInfo,
+			cinderv1beta1.DeploymentPausedMessage))
+		return ctrl.Result{}, nil
+	}
+
 	// Deploy a statefulset
-	ssDef := cindervolume.StatefulSet(instance, inputHash, serviceLabels, serviceAnnotations)
+	ssDef := cindervolume.StatefulSet(instance, inputHash, serviceLabels, serviceAnnotations, hostAliases, r.DefaultNodeSelector)
+
+	// Withhold the rollout of changed pods if requested
+	deferred, err := rolloutDeferred(ctx, helper, instance, ssDef)
+	if err != nil {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			condition.DeploymentReadyCondition,
+			condition.ErrorReason,
+			condition.SeverityWarning,
+			condition.DeploymentReadyErrorMessage,
+			err.Error()))
+		return ctrl.Result{}, err
+	}
+	if deferred {
+		Log.Info(fmt.Sprintf("Rollout of StatefulSet %s is deferred", ssDef.Name))
+		instance.Status.Conditions.Set(condition.TrueCondition(
+			cinderv1beta1.RolloutDeferredCondition,
+			cinderv1beta1.RolloutDeferredMessage))
+		// check again once the maintenance window might have opened
+		return ctrl.Result{RequeueAfter: cinder.GetRequeueInterval(instance.Spec.CinderTemplate, r.RequeueInterval)}, nil
+	}
+	instance.Status.Conditions.Remove(cinderv1beta1.RolloutDeferredCondition)
 	ss := statefulset.NewStatefulSet(
 		ssDef,
 		time.Duration(5)*time.Second,
@@ -541,6 +663,17 @@ func (r *CinderVolumeReconciler) reconcileNormal(ctx context.Context, instance *
 	}
 	instance.Status.ReadyCount = ss.GetStatefulSet().Status.ReadyReplicas
 
+	// report images referenced by tag and pods running another digest
+	driftCondition, err := imageDrift(ctx, helper, instance.Spec.ContainerImage, cindervolume.ComponentName, instance.Namespace, serviceLabels)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if driftCondition != nil {
+		instance.Status.Conditions.Set(driftCondition)
+	} else {
+		instance.Status.Conditions.Remove(cinderv1beta1.ImageDriftCondition)
+	}
+
 	// verify if network attachment matches expectations
 	networkReady := false
 	networkAttachmentStatus := map[string][]string{}
@@ -620,10 +753,11 @@ func (r *CinderVolumeReconciler) getSecret(
 		if k8s_errors.IsNotFound(err) {
 			instance.Status.Conditions.Set(condition.FalseCondition(
 				condition.InputReadyCondition,
-				condition.RequestedReason,
+				cinderv1beta1.SecretMissingReason,
 				condition.SeverityInfo,
-				condition.InputReadyWaitingMessage))
-			return ctrl.Result{RequeueAfter: time.Duration(10) * time.Second}, fmt.Errorf("Secret %s not found", secretName)
+				cinderv1beta1.InputReadySecretMissingMessage,
+				secretName))
+			return ctrl.Result{RequeueAfter: cinder.GetRequeueInterval(instance.Spec.CinderTemplate, r.RequeueInterval)}, fmt.Errorf("Secret %s not found", secretName)
 		}
 		instance.Status.Conditions.Set(condition.FalseCondition(
 			condition.InputReadyCondition,
@@ -701,6 +835,31 @@ func (r *CinderVolumeReconciler) generateServiceConfigs(
 		}
 	}
 
+	if len(instance.Spec.BackendPools) > 0 {
+		templateParameters["BackendPools"] = instance.Spec.BackendPools
+	}
+
+	if len(instance.Spec.ReplicationDevices) > 0 {
+		templateParameters["ReplicationDevices"] = cindervolume.GetReplicationDevices(instance.Spec.ReplicationDevices)
+	}
+
+	if instance.Spec.ExecutorThreadPoolSize != nil {
+		templateParameters["ExecutorThreadPoolSize"] = strconv.Itoa(int(*instance.Spec.ExecutorThreadPoolSize))
+	}
+
+	if instance.Spec.GracefulShutdownTimeout != nil {
+		templateParameters["GracefulShutdownTimeout"] = strconv.Itoa(int(*instance.Spec.GracefulShutdownTimeout))
+	}
+
+	if instance.Spec.BackendNativeThreadsPoolSize != nil {
+		templateParameters["BackendNativeThreadsPoolSize"] = strconv.Itoa(int(*instance.Spec.BackendNativeThreadsPoolSize))
+	}
+
+	if instance.Spec.HostSuffix != "" {
+		// cinder-volume can only have one replica, so the pod name is stable
+		templateParameters["ServiceHost"] = fmt.Sprintf("%s-0.%s", instance.Name, instance.Spec.HostSuffix)
+	}
+
 	configTemplates := []util.Template{
 		{
 			Name:          fmt.Sprintf("%s-config-data", instance.Name),
@@ -734,10 +893,18 @@ func (r *CinderVolumeReconciler) createHashOfInputHashes(
 	if err != nil {
 		return hash, changed, err
 	}
+	previousHash := instance.Status.Hash[common.InputHashName]
 	if hashMap, changed = util.SetHash(instance.Status.Hash, common.InputHashName, hash); changed {
 		instance.Status.Hash = hashMap
 		Log.Info(fmt.Sprintf("Input maps hash %s - %s", common.InputHashName, hash))
+		// an initial hash doesn't roll out existing pods
+		if previousHash != "" {
+			r.Recorder.Eventf(instance, corev1.EventTypeNormal, "InputHashChanged",
+				"Input hash changed from %s to %s, rolling out the pods. Changed inputs: %s",
+				previousHash, hash, strings.Join(changedInputs(instance.Status.Hash, mergedMapVars), ", "))
+		}
 	}
+	instance.Status.Hash = setInputHashes(instance.Status.Hash, mergedMapVars)
 	return hash, changed, nil
 }
 
@@ -22,6 +22,7 @@ import (
 	"flag"
 	"os"
 	"strings"
+	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
@@ -47,6 +48,7 @@ import (
 
 	cinderv1beta1 "github.com/openstack-k8s-operators/cinder-operator/api/v1beta1"
 	"github.com/openstack-k8s-operators/cinder-operator/controllers"
+	"github.com/openstack-k8s-operators/cinder-operator/pkg/cinder"
 	//+kubebuilder:scaffold:imports
 )
 
@@ -72,12 +74,24 @@ func main() {
 	var enableLeaderElection bool
 	var probeAddr string
 	var enableHTTP2 bool
+	var reconcileTimeout time.Duration
+	var requeueInterval time.Duration
+	var defaultNodeSelector string
 	flag.BoolVar(&enableHTTP2, "enable-http2", enableHTTP2, "If HTTP/2 should be enabled for the metrics and webhook servers.")
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
+	flag.DurationVar(&reconcileTimeout, "reconcile-timeout", 0,
+		"Deadline of a single reconcile, so a stalled Keystone or DB call fails and gets requeued "+
+			"instead of blocking the controller. Disabled when 0.")
+	flag.DurationVar(&requeueInterval, "requeue-interval", 10*time.Second,
+		"Interval at which a CR waiting on a dependency, e.g. a Secret or the Memcached instance, is "+
+			"reconciled again. Can be overridden by the requeueInterval of the CR.")
+	flag.StringVar(&defaultNodeSelector, "default-node-selector", "",
+		"Comma separated key=value node labels merged into the node selector of all the Cinder pods, "+
+			"e.g. to pin them to storage nodes. The nodeSelector of the CRs takes precedence.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -86,6 +100,12 @@ func main() {
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
+	nodeSelector, err := cinder.ParseNodeSelector(defaultNodeSelector)
+	if err != nil {
+		setupLog.Error(err, "invalid default-node-selector")
+		os.Exit(1)
+	}
+
 	disableHTTP2 := func(c *tls.Config) {
 		if enableHTTP2 {
 			return
@@ -124,41 +144,60 @@ func main() {
 	}
 
 	if err = (&controllers.CinderReconciler{
-		Client:  mgr.GetClient(),
-		Scheme:  mgr.GetScheme(),
-		Kclient: kclient,
+		Client:              mgr.GetClient(),
+		Scheme:              mgr.GetScheme(),
+		Kclient:             kclient,
+		ReconcileTimeout:    reconcileTimeout,
+		RequeueInterval:     requeueInterval,
+		DefaultNodeSelector: nodeSelector,
 	}).SetupWithManager(context.Background(), mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Cinder")
 		os.Exit(1)
 	}
 	if err = (&controllers.CinderAPIReconciler{
-		Client:  mgr.GetClient(),
-		Scheme:  mgr.GetScheme(),
-		Kclient: kclient,
+		Client:              mgr.GetClient(),
+		Scheme:              mgr.GetScheme(),
+		Kclient:             kclient,
+		ReconcileTimeout:    reconcileTimeout,
+		RequeueInterval:     requeueInterval,
+		DefaultNodeSelector: nodeSelector,
+		Recorder:            mgr.GetEventRecorderFor("cinderapi-controller"),
 	}).SetupWithManager(context.Background(), mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "CinderAPI")
 		os.Exit(1)
 	}
 	if err = (&controllers.CinderBackupReconciler{
-		Client:  mgr.GetClient(),
-		Scheme:  mgr.GetScheme(),
-		Kclient: kclient,
+		Client:              mgr.GetClient(),
+		Scheme:              mgr.GetScheme(),
+		Kclient:             kclient,
+		ReconcileTimeout:    reconcileTimeout,
+		RequeueInterval:     requeueInterval,
+		DefaultNodeSelector: nodeSelector,
+		Recorder:            mgr.GetEventRecorderFor("cinderbackup-controller"),
 	}).SetupWithManager(context.Background(), mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "CinderBackup")
 		os.Exit(1)
 	}
 	if err = (&controllers.CinderSchedulerReconciler{
-		Client:  mgr.GetClient(),
-		Scheme:  mgr.GetScheme(),
-		Kclient: kclient,
+		Client:              mgr.GetClient(),
+		Scheme:              mgr.GetScheme(),
+		Kclient:             kclient,
+		ReconcileTimeout:    reconcileTimeout,
+		RequeueInterval:     requeueInterval,
+		DefaultNodeSelector: nodeSelector,
+		Recorder:            mgr.GetEventRecorderFor("cinderscheduler-controller"),
 	}).SetupWithManager(context.Background(), mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "CinderScheduler")
 		os.Exit(1)
 	}
 	if err = (&controllers.CinderVolumeReconciler{
-		Client:  mgr.GetClient(),
-		Scheme:  mgr.GetScheme(),
-		Kclient: kclient,
+		Client:              mgr.GetClient(),
+		Scheme:              mgr.GetScheme(),
+		Kclient:             kclient,
+		ReconcileTimeout:    reconcileTimeout,
+		RequeueInterval:     requeueInterval,
+		DefaultNodeSelector: nodeSelector,
+		Recorder:            mgr.GetEventRecorderFor("cindervolume-controller"),
 	}).SetupWithManager(context.Background(), mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "CinderVolume")
 		os.Exit(1)
@@ -175,6 +214,10 @@ func main() {
 			setupLog.Error(err, "unable to create webhook", "webhook", "Cinder")
 			os.Exit(1)
 		}
+		if err = (&cinderv1beta1.CinderAPI{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "CinderAPI")
+			os.Exit(1)
+		}
 		checker = mgr.GetWebhookServer().StartedChecker()
 	}
 
@@ -16,6 +16,8 @@ limitations under the License.
 package cinder
 
 import (
+	"time"
+
 	"github.com/openstack-k8s-operators/lib-common/modules/storage"
 )
 
@@ -47,6 +49,29 @@ const (
 	// CinderInternalPort -
 	CinderInternalPort int32 = 8776
 
+	// TransportTLSCertsPath - path where the RabbitMQ TLS certs get mounted
+	TransportTLSCertsPath = "/etc/pki/cinder/rabbitmq"
+
+	// ConfigHashLabel - pod label holding the short config hash the pod was started with
+	ConfigHashLabel = "cinder.openstack.org/config-hash"
+	// configHashLabelLength - length of the config hash kept in the ConfigHashLabel
+	configHashLabelLength = 10
+
+	// GlanceCACertsPath - path where the Glance API CA gets mounted
+	GlanceCACertsPath = "/etc/pki/cinder/glance"
+
+	// ExtraCABundlesPath - directory where each extra CA bundle Secret gets mounted
+	ExtraCABundlesPath = "/etc/pki/cinder/ca-bundles"
+
+	// DefaultAPIWorkers - number of cinder-api worker processes
+	DefaultAPIWorkers = 4
+
+	// DefaultRequeueInterval - interval at which a CR waiting on a dependency is reconciled again
+	DefaultRequeueInterval = 10 * time.Second
+
+	// KollaConfigFile - default path of the kolla config file
+	KollaConfigFile = "/var/lib/kolla/config_files/config.json"
+
 	// CinderExtraVolTypeUndefined can be used to label an extraMount which
 	// is not associated with a specific backend
 	CinderExtraVolTypeUndefined storage.ExtraVolType = "Undefined"
@@ -31,6 +31,7 @@ func CronJob(
 	instance *cinderv1.Cinder,
 	labels map[string]string,
 	annotations map[string]string,
+	defaultNodeSelector map[string]string,
 ) *batchv1.CronJob {
 	cinderUser := int64(cinderv1.CinderUserID)
 	cinderGroup := int64(cinderv1.CinderGroupID)
@@ -131,8 +132,7 @@ func CronJob(
 			},
 		},
 	}
-	if instance.Spec.NodeSelector != nil && len(instance.Spec.NodeSelector) > 0 {
-		cronjob.Spec.JobTemplate.Spec.Template.Spec.NodeSelector = instance.Spec.NodeSelector
-	}
+	cronjob.Spec.JobTemplate.Spec.Template.Spec.NodeSelector = GetNodeSelector(
+		instance.Spec.NodeSelector, defaultNodeSelector)
 	return cronjob
 }
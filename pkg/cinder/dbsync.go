@@ -21,6 +21,11 @@ const (
 	DBSyncCommand = "/usr/local/bin/kolla_set_configs && /usr/local/bin/kolla_start"
 )
 
+// GetDbSyncJobName - returns the name of the db sync Job of the Cinder instance
+func GetDbSyncJobName(instance *cinderv1beta1.Cinder) string {
+	return instance.Name + "-db-sync"
+}
+
 // DbSyncJob func
 func DbSyncJob(instance *cinderv1beta1.Cinder, labels map[string]string, annotations map[string]string) *batchv1.Job {
 	var config0644AccessMode int32 = 0644
@@ -52,6 +57,8 @@ func DbSyncJob(instance *cinderv1beta1.Cinder, labels map[string]string, annotat
 		},
 	}
 
+	// The job runs with the cinder-api image
+	kollaConfigFile := GetKollaConfigFile(instance.Spec.CinderAPI.KollaConfigFile)
 	dbSyncMounts := []corev1.VolumeMount{
 		{
 			Name:      "db-sync-config-data",
@@ -60,7 +67,7 @@ func DbSyncJob(instance *cinderv1beta1.Cinder, labels map[string]string, annotat
 		},
 		{
 			Name:      "config-data",
-			MountPath: "/var/lib/kolla/config_files/config.json",
+			MountPath: kollaConfigFile,
 			SubPath:   "db-sync-config.json",
 			ReadOnly:  true,
 		},
@@ -84,11 +91,12 @@ func DbSyncJob(instance *cinderv1beta1.Cinder, labels map[string]string, annotat
 	runAsUser := int64(0)
 	envVars := map[string]env.Setter{}
 	envVars["KOLLA_CONFIG_STRATEGY"] = env.SetValue("COPY_ALWAYS")
+	envVars["KOLLA_CONFIG_FILE"] = env.SetValue(kollaConfigFile)
 	envVars["KOLLA_BOOTSTRAP"] = env.SetValue("TRUE")
 
 	job := &batchv1.Job{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      instance.Name + "-db-sync",
+			Name:      GetDbSyncJobName(instance),
 			Namespace: instance.Namespace,
 			Labels:    labels,
 		},
@@ -1,10 +1,23 @@
 package cinder
 
 import (
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"time"
+
+	cinderv1beta1 "github.com/openstack-k8s-operators/cinder-operator/api/v1beta1"
 	common "github.com/openstack-k8s-operators/lib-common/modules/common"
 	"github.com/openstack-k8s-operators/lib-common/modules/common/affinity"
+	"github.com/openstack-k8s-operators/lib-common/modules/common/service"
+	"github.com/openstack-k8s-operators/lib-common/modules/common/util"
 
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -47,3 +60,247 @@ func GetPodAffinity(componentName string) *corev1.Affinity {
 		corev1.LabelHostname,
 	)
 }
+
+// AddComponentAntiAffinity - Adds to podAffinity a rule keeping the pods away from the nodes
+// running the pods of the given components, as a preference (soft) or a requirement (hard).
+func AddComponentAntiAffinity(podAffinity *corev1.Affinity, policy string, componentNames []string) *corev1.Affinity {
+	term := corev1.PodAffinityTerm{
+		LabelSelector: &metav1.LabelSelector{
+			MatchExpressions: []metav1.LabelSelectorRequirement{
+				{
+					Key:      common.ComponentSelector,
+					Operator: metav1.LabelSelectorOpIn,
+					Values:   componentNames,
+				},
+			},
+		},
+		TopologyKey: corev1.LabelHostname,
+	}
+
+	switch policy {
+	case cinderv1beta1.ComponentAntiAffinitySoft:
+		podAffinity.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution = append(
+			podAffinity.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution,
+			corev1.WeightedPodAffinityTerm{PodAffinityTerm: term, Weight: 100},
+		)
+	case cinderv1beta1.ComponentAntiAffinityHard:
+		podAffinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution = append(
+			podAffinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution,
+			term,
+		)
+	}
+
+	return podAffinity
+}
+
+// GetHostAliases - Returns the pod hostAliases for the data of a ConfigMap mapping hostnames
+// to IP addresses, grouped by IP address and sorted. Malformed entries are rejected.
+func GetHostAliases(data map[string]string) ([]corev1.HostAlias, error) {
+	hostnames := map[string][]string{}
+	for hostname, ip := range data {
+		if errs := validation.IsDNS1123Subdomain(hostname); len(errs) > 0 {
+			return nil, fmt.Errorf("invalid hostname %s: %s", hostname, strings.Join(errs, ", "))
+		}
+		addr := net.ParseIP(strings.TrimSpace(ip))
+		if addr == nil {
+			return nil, fmt.Errorf("invalid IP address %q for hostname %s", ip, hostname)
+		}
+		hostnames[addr.String()] = append(hostnames[addr.String()], hostname)
+	}
+
+	hostAliases := []corev1.HostAlias{}
+	for ip, names := range hostnames {
+		sort.Strings(names)
+		hostAliases = append(hostAliases, corev1.HostAlias{IP: ip, Hostnames: names})
+	}
+	sort.Slice(hostAliases, func(i, j int) bool {
+		return hostAliases[i].IP < hostAliases[j].IP
+	})
+
+	return hostAliases, nil
+}
+
+// GetNodeSelector - Returns the node selector of the pods, which is the NodeSelector of the spec
+// merged with the default node selector of the operator, the keys of the spec taking precedence.
+// It is nil when both are empty.
+func GetNodeSelector(nodeSelector map[string]string, defaultNodeSelector map[string]string) map[string]string {
+	return util.MergeStringMaps(nodeSelector, defaultNodeSelector)
+}
+
+// ParseNodeSelector - Returns the node selector of a comma separated list of key=value pairs,
+// e.g. the default node selector passed to the operator
+func ParseNodeSelector(value string) (map[string]string, error) {
+	nodeSelector := map[string]string{}
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		key, val, found := strings.Cut(pair, "=")
+		if !found {
+			return nil, fmt.Errorf("invalid node selector %q, expected key=value", pair)
+		}
+		nodeSelector[strings.TrimSpace(key)] = strings.TrimSpace(val)
+	}
+
+	if errs := metav1validation.ValidateLabels(nodeSelector, field.NewPath("nodeSelector")); len(errs) > 0 {
+		return nil, errs.ToAggregate()
+	}
+
+	return nodeSelector, nil
+}
+
+// GetRequeueInterval - Returns the interval at which a CR waiting on a dependency is reconciled
+// again, which is the RequeueInterval of the CR when set, else the interval of the controller when
+// set, else DefaultRequeueInterval.
+func GetRequeueInterval(tmpl cinderv1beta1.CinderTemplate, controllerInterval time.Duration) time.Duration {
+	if tmpl.RequeueInterval != nil && tmpl.RequeueInterval.Duration > 0 {
+		return tmpl.RequeueInterval.Duration
+	}
+	if controllerInterval > 0 {
+		return controllerInterval
+	}
+
+	return DefaultRequeueInterval
+}
+
+// GetMissingSecretKey - Returns the first of the keys which does not exist in the Secret, or an
+// empty string when all of them exist.
+func GetMissingSecretKey(secret *corev1.Secret, keys ...string) string {
+	for _, key := range keys {
+		if _, ok := secret.Data[key]; !ok {
+			return key
+		}
+	}
+
+	return ""
+}
+
+// GetAPIWorkers - Returns the number of cinder-api worker processes, which is the APIWorkers
+// override when set, or the CPU limit of the container rounded up when WorkersFromCPULimit is
+// enabled and a limit is set.
+func GetAPIWorkers(apiTemplate cinderv1beta1.CinderAPITemplate) int {
+	if apiTemplate.APIWorkers != nil {
+		return int(*apiTemplate.APIWorkers)
+	}
+
+	cpuLimit, ok := apiTemplate.Resources.Limits[corev1.ResourceCPU]
+	if !apiTemplate.WorkersFromCPULimit || !ok || cpuLimit.IsZero() {
+		return DefaultAPIWorkers
+	}
+
+	return int((cpuLimit.MilliValue() + 999) / 1000)
+}
+
+// GetAPIPort - Returns the port of the cinder-api endpoint type, the APIPorts override
+// when set or the default port of the endpoint type.
+func GetAPIPort(apiTemplate cinderv1beta1.CinderAPITemplate, endpt service.Endpoint) int32 {
+	if port, ok := apiTemplate.APIPorts[endpt]; ok && port != 0 {
+		return port
+	}
+	if endpt == service.EndpointInternal {
+		return CinderInternalPort
+	}
+	return CinderPublicPort
+}
+
+// GetAPIServiceName - Returns the name of the Service of the cinder-api endpoint type, based on
+// the ServiceName override when set or the cinder service name.
+func GetAPIServiceName(apiTemplate cinderv1beta1.CinderAPITemplate, endpt service.Endpoint) string {
+	name := ServiceName
+	if apiTemplate.ServiceName != "" {
+		name = apiTemplate.ServiceName
+	}
+	return name + "-" + string(endpt)
+}
+
+// GetAPIListenPorts - Returns the sorted distinct ports the cinder-api httpd listens on.
+func GetAPIListenPorts(apiTemplate cinderv1beta1.CinderAPITemplate) []int32 {
+	ports := []int32{}
+	seen := map[int32]bool{}
+	for _, endpt := range []service.Endpoint{service.EndpointInternal, service.EndpointPublic} {
+		port := GetAPIPort(apiTemplate, endpt)
+		if !seen[port] {
+			seen[port] = true
+			ports = append(ports, port)
+		}
+	}
+	sort.Slice(ports, func(i, j int) bool { return ports[i] < ports[j] })
+	return ports
+}
+
+// GetImageDigest - Returns the digest an image reference is pinned to (e.g. sha256:...),
+// or an empty string when the image is referenced by a tag.
+func GetImageDigest(image string) string {
+	if i := strings.LastIndex(image, "@"); i >= 0 {
+		return image[i+1:]
+	}
+
+	return ""
+}
+
+// IsRolloutDeferred - Returns true if the annotations of a CinderAPI, CinderScheduler,
+// CinderBackup or CinderVolume object request to withhold the rollout of its pods at
+// the given time, either explicitly or because it is outside the maintenance window.
+func IsRolloutDeferred(annotations map[string]string, now time.Time) (bool, error) {
+	if annotations[cinderv1beta1.DeferRolloutAnnotation] == "true" {
+		return true, nil
+	}
+
+	window, ok := annotations[cinderv1beta1.MaintenanceWindowAnnotation]
+	if !ok || window == "" {
+		return false, nil
+	}
+
+	bounds := strings.Split(window, "-")
+	if len(bounds) != 2 {
+		return false, fmt.Errorf("invalid maintenance window %q, expected HH:MM-HH:MM", window)
+	}
+	start, err := time.Parse("15:04", strings.TrimSpace(bounds[0]))
+	if err != nil {
+		return false, fmt.Errorf("invalid maintenance window %q: %w", window, err)
+	}
+	end, err := time.Parse("15:04", strings.TrimSpace(bounds[1]))
+	if err != nil {
+		return false, fmt.Errorf("invalid maintenance window %q: %w", window, err)
+	}
+
+	now = now.UTC()
+	minutes := now.Hour()*60 + now.Minute()
+	startMinutes := start.Hour()*60 + start.Minute()
+	endMinutes := end.Hour()*60 + end.Minute()
+
+	var inWindow bool
+	if startMinutes <= endMinutes {
+		inWindow = minutes >= startMinutes && minutes < endMinutes
+	} else {
+		// the window spans midnight
+		inWindow = minutes >= startMinutes || minutes < endMinutes
+	}
+
+	return !inWindow, nil
+}
+
+// GetKollaConfigFile - returns the path of the kolla config file, falling
+// back to the kolla default when none is set
+func GetKollaConfigFile(kollaConfigFile string) string {
+	if kollaConfigFile == "" {
+		return KollaConfigFile
+	}
+	return kollaConfigFile
+}
+
+// GetPodLabels - Returns the labels of the pods of a service, which are the
+// service labels plus the short config hash, so pods running a stale config
+// can be told apart during a rollout
+func GetPodLabels(labels map[string]string, configHash string) map[string]string {
+	podLabels := make(map[string]string, len(labels)+1)
+	for k, v := range labels {
+		podLabels[k] = v
+	}
+	if len(configHash) > configHashLabelLength {
+		configHash = configHash[:configHashLabelLength]
+	}
+	podLabels[ConfigHashLabel] = configHash
+	return podLabels
+}
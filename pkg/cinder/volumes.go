@@ -1,6 +1,9 @@
 package cinder
 
 import (
+	"fmt"
+	"path/filepath"
+
 	cinderv1beta1 "github.com/openstack-k8s-operators/cinder-operator/api/v1beta1"
 	"github.com/openstack-k8s-operators/lib-common/modules/storage"
 	corev1 "k8s.io/api/core/v1"
@@ -149,6 +152,79 @@ func GetVolumes(name string, storageSvc bool, extraVol []cinderv1beta1.CinderExt
 	return res
 }
 
+// GetTransportTLSVolume - volume of the Secret holding the RabbitMQ TLS certs
+func GetTransportTLSVolume(secretName string) corev1.Volume {
+	var config0644AccessMode int32 = 0644
+
+	return corev1.Volume{
+		Name: "transport-tls-certs",
+		VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{
+				DefaultMode: &config0644AccessMode,
+				SecretName:  secretName,
+			},
+		},
+	}
+}
+
+// GetTransportTLSVolumeMount - mount of the RabbitMQ TLS certs
+func GetTransportTLSVolumeMount() corev1.VolumeMount {
+	return corev1.VolumeMount{
+		Name:      "transport-tls-certs",
+		MountPath: TransportTLSCertsPath,
+		ReadOnly:  true,
+	}
+}
+
+// GetGlanceCAVolume - volume of the Secret holding the Glance API CA
+func GetGlanceCAVolume(secretName string) corev1.Volume {
+	var config0644AccessMode int32 = 0644
+
+	return corev1.Volume{
+		Name: "glance-ca-certs",
+		VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{
+				DefaultMode: &config0644AccessMode,
+				SecretName:  secretName,
+			},
+		},
+	}
+}
+
+// GetGlanceCAVolumeMount - mount of the Glance API CA
+func GetGlanceCAVolumeMount() corev1.VolumeMount {
+	return corev1.VolumeMount{
+		Name:      "glance-ca-certs",
+		MountPath: GlanceCACertsPath,
+		ReadOnly:  true,
+	}
+}
+
+// GetExtraCABundleVolume - volume of an extra CA bundle Secret, named after its
+// index as the Secret name may exceed the length allowed for a volume name
+func GetExtraCABundleVolume(index int, secretName string) corev1.Volume {
+	var config0644AccessMode int32 = 0644
+
+	return corev1.Volume{
+		Name: fmt.Sprintf("extra-ca-bundle-%d", index),
+		VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{
+				DefaultMode: &config0644AccessMode,
+				SecretName:  secretName,
+			},
+		},
+	}
+}
+
+// GetExtraCABundleVolumeMount - mount of an extra CA bundle Secret
+func GetExtraCABundleVolumeMount(index int, secretName string) corev1.VolumeMount {
+	return corev1.VolumeMount{
+		Name:      fmt.Sprintf("extra-ca-bundle-%d", index),
+		MountPath: filepath.Join(ExtraCABundlesPath, secretName),
+		ReadOnly:  true,
+	}
+}
+
 // GetVolumeMounts - Cinder Control Plane VolumeMounts
 func GetVolumeMounts(storageSvc bool, extraVol []cinderv1beta1.CinderExtraVolMounts, svc []storage.PropagationType) []corev1.VolumeMount {
 	res := []corev1.VolumeMount{
@@ -21,4 +21,11 @@ const (
 
 	//LogFile -
 	LogFile = "/var/log/cinder/cinder-api.log"
+
+	// DefaultLogCPURequest - CPU request of the log container when LogResources is unset
+	DefaultLogCPURequest = "5m"
+	// DefaultLogMemoryRequest - memory request of the log container when LogResources is unset
+	DefaultLogMemoryRequest = "32Mi"
+	// DefaultLogMemoryLimit - memory limit of the log container when LogResources is unset
+	DefaultLogMemoryLimit = "64Mi"
 )
@@ -0,0 +1,71 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cinderapi
+
+import (
+	cinderv1beta1 "github.com/openstack-k8s-operators/cinder-operator/api/v1beta1"
+
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+// HorizontalPodAutoscaler func
+func HorizontalPodAutoscaler(
+	instance *cinderv1beta1.CinderAPI,
+	labels map[string]string,
+) *autoscalingv2.HorizontalPodAutoscaler {
+	autoscaling := instance.Spec.Autoscaling
+
+	minReplicas := ptr.To(int32(1))
+	if autoscaling.MinReplicas != nil {
+		minReplicas = autoscaling.MinReplicas
+	}
+	targetCPUUtilization := ptr.To(int32(80))
+	if autoscaling.TargetCPUUtilization != nil {
+		targetCPUUtilization = autoscaling.TargetCPUUtilization
+	}
+
+	return &autoscalingv2.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      instance.Name,
+			Namespace: instance.Namespace,
+			Labels:    labels,
+		},
+		Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{
+				APIVersion: "apps/v1",
+				Kind:       "StatefulSet",
+				Name:       instance.Name,
+			},
+			MinReplicas: minReplicas,
+			MaxReplicas: autoscaling.MaxReplicas,
+			Metrics: []autoscalingv2.MetricSpec{
+				{
+					Type: autoscalingv2.ResourceMetricSourceType,
+					Resource: &autoscalingv2.ResourceMetricSource{
+						Name: corev1.ResourceCPU,
+						Target: autoscalingv2.MetricTarget{
+							Type:               autoscalingv2.UtilizationMetricType,
+							AverageUtilization: targetCPUUtilization,
+						},
+					},
+				},
+			},
+		},
+	}
+}
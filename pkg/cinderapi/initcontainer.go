@@ -0,0 +1,63 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cinderapi
+
+import (
+	cinderv1beta1 "github.com/openstack-k8s-operators/cinder-operator/api/v1beta1"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// DBWaitContainerName - name of the init container waiting for the database
+	DBWaitContainerName = cinderv1beta1.CinderAPIDBWaitContainerName
+
+	// DBWaitCommand - reads the database host, and optional port, from the
+	// connection of the rendered config, and waits until it accepts connections
+	DBWaitCommand = `DB_HOST=$(sed -n 's|^connection = .*@\([^/?]*\).*|\1|p' /var/lib/config-data/merged/00-global-defaults.conf | head -n 1)
+DB_PORT=3306
+case "${DB_HOST}" in
+  *:*) DB_PORT="${DB_HOST##*:}"; DB_HOST="${DB_HOST%:*}";;
+esac
+until timeout 2 bash -c "</dev/tcp/${DB_HOST}/${DB_PORT}" 2>/dev/null; do
+  echo "Waiting for the database at ${DB_HOST}:${DB_PORT}"
+  sleep 2
+done`
+)
+
+// GetDBWaitInitContainer - init container holding the start of the cinder-api
+// until the database is reachable, which avoids crash loops of the API while
+// the database is starting
+func GetDBWaitInitContainer(
+	instance *cinderv1beta1.CinderAPI,
+	securityContext *corev1.SecurityContext,
+) corev1.Container {
+	return corev1.Container{
+		Name:            DBWaitContainerName,
+		Image:           instance.Spec.ContainerImage,
+		Command:         []string{"/bin/bash"},
+		Args:            []string{"-c", DBWaitCommand},
+		SecurityContext: securityContext,
+		VolumeMounts: []corev1.VolumeMount{
+			{
+				Name:      "config-data",
+				MountPath: "/var/lib/config-data/merged",
+				ReadOnly:  true,
+			},
+		},
+		Resources: GetLogResources(instance),
+	}
+}
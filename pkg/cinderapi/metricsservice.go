@@ -0,0 +1,69 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cinderapi
+
+import (
+	cinderv1beta1 "github.com/openstack-k8s-operators/cinder-operator/api/v1beta1"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+const (
+	// MetricsPortName - name of the port of the metrics service
+	MetricsPortName = "metrics"
+
+	// MetricsPath - path of the cinder-api metrics
+	MetricsPath = "/metrics"
+)
+
+// GetMetricsServiceName - Returns the name of the ClusterIP service exposing the cinder-api
+// metrics, based on the ServiceName of the CinderAPI if set, or on its name otherwise.
+func GetMetricsServiceName(instance *cinderv1beta1.CinderAPI) string {
+	name := instance.Name
+	if instance.Spec.ServiceName != "" {
+		name = instance.Spec.ServiceName
+	}
+	return name + "-metrics"
+}
+
+// MetricsService func
+func MetricsService(
+	instance *cinderv1beta1.CinderAPI,
+	labels map[string]string,
+	selector map[string]string,
+) *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      GetMetricsServiceName(instance),
+			Namespace: instance.Namespace,
+			Labels:    labels,
+		},
+		Spec: corev1.ServiceSpec{
+			Type:     corev1.ServiceTypeClusterIP,
+			Selector: selector,
+			Ports: []corev1.ServicePort{
+				{
+					Name:       MetricsPortName,
+					Port:       instance.Spec.MetricsPort,
+					TargetPort: intstr.FromInt32(instance.Spec.MetricsPort),
+					Protocol:   corev1.ProtocolTCP,
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,77 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cinderapi
+
+import (
+	cinderv1beta1 "github.com/openstack-k8s-operators/cinder-operator/api/v1beta1"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// ServiceMonitorGVK - the Prometheus Operator ServiceMonitor, which is built
+// as unstructured as its CRD is optional
+var ServiceMonitorGVK = schema.GroupVersionKind{
+	Group:   "monitoring.coreos.com",
+	Version: "v1",
+	Kind:    "ServiceMonitor",
+}
+
+// ServiceMonitor func
+func ServiceMonitor(
+	instance *cinderv1beta1.CinderAPI,
+	labels map[string]string,
+	selector map[string]string,
+	portName string,
+	scheme string,
+) *unstructured.Unstructured {
+	matchLabels := map[string]interface{}{}
+	for k, v := range selector {
+		matchLabels[k] = v
+	}
+	objLabels := map[string]interface{}{}
+	for k, v := range labels {
+		objLabels[k] = v
+	}
+
+	serviceMonitor := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"name":      instance.Name,
+				"namespace": instance.Namespace,
+				"labels":    objLabels,
+			},
+			"spec": map[string]interface{}{
+				"selector": map[string]interface{}{
+					"matchLabels": matchLabels,
+				},
+				"namespaceSelector": map[string]interface{}{
+					"matchNames": []interface{}{instance.Namespace},
+				},
+				"endpoints": []interface{}{
+					map[string]interface{}{
+						"port":   portName,
+						"path":   MetricsPath,
+						"scheme": scheme,
+					},
+				},
+			},
+		},
+	}
+	serviceMonitor.SetGroupVersionKind(ServiceMonitorGVK)
+
+	return serviceMonitor
+}
@@ -16,15 +16,22 @@ limitations under the License.
 package cinderapi
 
 import (
+	"fmt"
+	"strconv"
+
 	cinderv1beta1 "github.com/openstack-k8s-operators/cinder-operator/api/v1beta1"
 	cinder "github.com/openstack-k8s-operators/cinder-operator/pkg/cinder"
+	"github.com/openstack-k8s-operators/cinder-operator/pkg/cinderscheduler"
+	"github.com/openstack-k8s-operators/cinder-operator/pkg/cindervolume"
 	common "github.com/openstack-k8s-operators/lib-common/modules/common"
 	"github.com/openstack-k8s-operators/lib-common/modules/common/env"
 	"github.com/openstack-k8s-operators/lib-common/modules/common/service"
 	"github.com/openstack-k8s-operators/lib-common/modules/common/tls"
+	"github.com/openstack-k8s-operators/lib-common/modules/common/util"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 )
@@ -32,6 +39,13 @@ import (
 const (
 	// ServiceCommand -
 	ServiceCommand = "/usr/local/bin/kolla_set_configs && /usr/local/bin/kolla_start"
+
+	// DefaultHealthCheckPath - path of the healthcheck probed when none is set
+	DefaultHealthCheckPath = "/healthcheck"
+
+	// PreStopSleepSeconds - seconds the preStop hook waits for the pod to be
+	// removed from the endpoints of the services before httpd stops
+	PreStopSleepSeconds = 5
 )
 
 // StatefulSet func
@@ -40,23 +54,38 @@ func StatefulSet(
 	configHash string,
 	labels map[string]string,
 	annotations map[string]string,
+	hostAliases []corev1.HostAlias,
+	defaultNodeSelector map[string]string,
 ) (*appsv1.StatefulSet, error) {
 	runAsUser := int64(0)
+	if instance.Spec.SecurityContext.RunAsUser != nil {
+		runAsUser = *instance.Spec.SecurityContext.RunAsUser
+	}
 
 	livenessProbe := &corev1.Probe{
-		// TODO might need tuning
 		TimeoutSeconds:      5,
 		PeriodSeconds:       3,
 		InitialDelaySeconds: 5,
+		FailureThreshold:    instance.Spec.LivenessFailureThreshold,
 	}
 	readinessProbe := &corev1.Probe{
-		// TODO might need tuning
 		TimeoutSeconds:      5,
 		PeriodSeconds:       5,
 		InitialDelaySeconds: 5,
+		FailureThreshold:    instance.Spec.ReadinessFailureThreshold,
 	}
+	// suppresses the liveness probe until kolla_set_configs completed and
+	// httpd is serving, only set when the service runs
+	startupProbe := &corev1.Probe{
+		TimeoutSeconds:      5,
+		PeriodSeconds:       5,
+		InitialDelaySeconds: 5,
+		FailureThreshold:    12,
+	}
+	setProbeConfig(livenessProbe, readinessProbe, startupProbe, instance.Spec.ProbeConfig)
 
 	args := []string{"-c"}
+	var lifecycle *corev1.Lifecycle
 	if instance.Spec.Debug.Service {
 		args = append(args, common.DebugCommand)
 		livenessProbe.Exec = &corev1.ExecAction{
@@ -65,29 +94,86 @@ func StatefulSet(
 			},
 		}
 		readinessProbe.Exec = livenessProbe.Exec
+		startupProbe = nil
 	} else {
 		args = append(args, ServiceCommand)
 		//
 		// https://kubernetes.io/docs/tasks/configure-pod-container/configure-liveness-readiness-startup-probes/
 		//
+		probeEndpoint := instance.Spec.ProbeEndpoint
+		if probeEndpoint != service.EndpointInternal {
+			probeEndpoint = service.EndpointPublic
+		}
+		probePort := cinder.GetAPIPort(instance.Spec.CinderAPITemplate, probeEndpoint)
+
+		healthCheckPath := instance.Spec.HealthCheckPath
+		if healthCheckPath == "" {
+			healthCheckPath = DefaultHealthCheckPath
+		}
+
 		livenessProbe.HTTPGet = &corev1.HTTPGetAction{
-			Path: "/healthcheck",
-			Port: intstr.IntOrString{Type: intstr.Int, IntVal: int32(cinder.CinderPublicPort)},
+			Path: healthCheckPath,
+			Port: intstr.IntOrString{Type: intstr.Int, IntVal: int32(probePort)},
+			// both endpoints are served by the same httpd, so select the vhost
+			// of the probed endpoint
+			HTTPHeaders: []corev1.HTTPHeader{
+				{
+					Name:  "Host",
+					Value: fmt.Sprintf("%s.%s.svc", cinder.GetAPIServiceName(instance.Spec.CinderAPITemplate, probeEndpoint), instance.Namespace),
+				},
+			},
 		}
 		readinessProbe.HTTPGet = livenessProbe.HTTPGet
+		startupProbe.HTTPGet = livenessProbe.HTTPGet
 
-		if instance.Spec.TLS.API.Enabled(service.EndpointPublic) {
+		if instance.Spec.TLS.API.Enabled(probeEndpoint) {
 			livenessProbe.HTTPGet.Scheme = corev1.URISchemeHTTPS
-			readinessProbe.HTTPGet.Scheme = corev1.URISchemeHTTPS
 		}
+
+		if instance.Spec.TerminationGracePeriodSeconds != nil {
+			lifecycle = &corev1.Lifecycle{
+				PreStop: &corev1.LifecycleHandler{
+					Exec: &corev1.ExecAction{
+						Command: []string{"/bin/sleep", strconv.Itoa(PreStopSleepSeconds)},
+					},
+				},
+			}
+		}
+	}
+
+	// a custom entrypoint replaces both the bash command and its arguments
+	command := []string{"/bin/bash"}
+	if len(instance.Spec.ContainerCommand) > 0 {
+		command = instance.Spec.ContainerCommand
+	}
+	if len(instance.Spec.ContainerCommand) > 0 || len(instance.Spec.ContainerArgs) > 0 {
+		args = instance.Spec.ContainerArgs
+	}
+
+	logImage := instance.Spec.LogImage
+	if logImage == "" {
+		logImage = instance.Spec.ContainerImage
+	}
+
+	// spread the cinder-api pods unless the constraint selects other pods
+	var topologySpreadConstraints []corev1.TopologySpreadConstraint
+	for _, constraint := range instance.Spec.TopologySpreadConstraints {
+		if constraint.LabelSelector == nil {
+			constraint.LabelSelector = &metav1.LabelSelector{
+				MatchLabels: labels,
+			}
+		}
+		topologySpreadConstraints = append(topologySpreadConstraints, constraint)
 	}
 
+	kollaConfigFile := cinder.GetKollaConfigFile(instance.Spec.KollaConfigFile)
+
 	// create Volume and VolumeMounts
 	volumes := GetVolumes(
 		cinder.GetOwningCinderName(instance),
 		instance.Name,
 		instance.Spec.ExtraMounts)
-	volumeMounts := GetVolumeMounts(instance.Spec.ExtraMounts)
+	volumeMounts := GetVolumeMounts(kollaConfigFile, GetLogFile(instance), instance.Spec.ExtraMounts)
 
 	// add CA cert if defined
 	if instance.Spec.TLS.CaBundleSecretName != "" {
@@ -95,6 +181,24 @@ func StatefulSet(
 		volumeMounts = append(volumeMounts, instance.Spec.TLS.CreateVolumeMounts(nil)...)
 	}
 
+	// add the extra CA bundles
+	for i, secretName := range instance.Spec.ExtraCaBundleSecretNames {
+		volumes = append(volumes, cinder.GetExtraCABundleVolume(i, secretName))
+		volumeMounts = append(volumeMounts, cinder.GetExtraCABundleVolumeMount(i, secretName))
+	}
+
+	// add RabbitMQ TLS certs if defined
+	if instance.Spec.TransportTLSSecret != "" {
+		volumes = append(volumes, cinder.GetTransportTLSVolume(instance.Spec.TransportTLSSecret))
+		volumeMounts = append(volumeMounts, cinder.GetTransportTLSVolumeMount())
+	}
+
+	// add Glance API CA if defined
+	if instance.Spec.GlanceCASecret != "" {
+		volumes = append(volumes, cinder.GetGlanceCAVolume(instance.Spec.GlanceCASecret))
+		volumeMounts = append(volumeMounts, cinder.GetGlanceCAVolumeMount())
+	}
+
 	for _, endpt := range []service.Endpoint{service.EndpointInternal, service.EndpointPublic} {
 		if instance.Spec.TLS.API.Enabled(endpt) {
 			var tlsEndptCfg tls.GenericService
@@ -116,8 +220,26 @@ func StatefulSet(
 
 	envVars := map[string]env.Setter{}
 	envVars["KOLLA_CONFIG_STRATEGY"] = env.SetValue("COPY_ALWAYS")
+	envVars["KOLLA_CONFIG_FILE"] = env.SetValue(kollaConfigFile)
 	envVars["CONFIG_HASH"] = env.SetValue(configHash)
 
+	// the extra env vars of the API container are merged first, so they can't
+	// override the ones set by the operator
+	extraEnvVars := map[string]env.Setter{}
+	for name, value := range instance.Spec.ExtraEnv {
+		extraEnvVars[name] = env.SetValue(value)
+	}
+	apiEnv := env.MergeEnvs(env.MergeEnvs([]corev1.EnvVar{}, extraEnvVars), envVars)
+
+	// the debug pods start regardless of the database
+	var initContainers []corev1.Container
+	if !instance.Spec.Debug.Service && (instance.Spec.WaitForDatabase == nil || *instance.Spec.WaitForDatabase) {
+		initContainers = append(initContainers, GetDBWaitInitContainer(instance, &corev1.SecurityContext{
+			RunAsUser:  &runAsUser,
+			RunAsGroup: instance.Spec.SecurityContext.RunAsGroup,
+		}))
+	}
+
 	statefulset := &appsv1.StatefulSet{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      instance.Name,
@@ -131,16 +253,25 @@ func StatefulSet(
 			Replicas: instance.Spec.Replicas,
 			Template: corev1.PodTemplateSpec{
 				ObjectMeta: metav1.ObjectMeta{
-					Annotations: annotations,
-					Labels:      labels,
+					// the operator managed labels and annotations take precedence
+					Annotations: util.MergeStringMaps(annotations, instance.Spec.PodAnnotations),
+					Labels:      util.MergeStringMaps(cinder.GetPodLabels(labels, configHash), instance.Spec.PodLabels),
 				},
 				Spec: corev1.PodSpec{
 					ServiceAccountName: instance.Spec.ServiceAccount,
+					ImagePullSecrets:   instance.Spec.ImagePullSecrets,
+					PriorityClassName:  instance.Spec.PriorityClassName,
+					SecurityContext: &corev1.PodSecurityContext{
+						RunAsUser:  instance.Spec.SecurityContext.RunAsUser,
+						RunAsGroup: instance.Spec.SecurityContext.RunAsGroup,
+						FSGroup:    instance.Spec.SecurityContext.FSGroup,
+					},
+					InitContainers: initContainers,
 					Containers: []corev1.Container{
 						// the first container in a pod is the default selected
 						// by oc log so define the log stream container first.
 						{
-							Name: instance.Name + "-log",
+							Name: instance.Name + cinderv1beta1.CinderAPILogContainerSuffix,
 							Command: []string{
 								"/usr/bin/dumb-init",
 							},
@@ -150,40 +281,118 @@ func StatefulSet(
 								"/usr/bin/tail",
 								"-n+1",
 								"-F",
-								LogFile,
+								GetLogFile(instance),
 							},
-							Image: instance.Spec.ContainerImage,
+							Image: logImage,
 							SecurityContext: &corev1.SecurityContext{
-								RunAsUser: &runAsUser,
+								RunAsUser:  &runAsUser,
+								RunAsGroup: instance.Spec.SecurityContext.RunAsGroup,
 							},
 							Env:          env.MergeEnvs([]corev1.EnvVar{}, envVars),
-							VolumeMounts: []corev1.VolumeMount{GetLogVolumeMount()},
-							Resources:    instance.Spec.Resources,
+							VolumeMounts: []corev1.VolumeMount{GetLogVolumeMount(GetLogFile(instance))},
+							Resources:    GetLogResources(instance),
 						},
 						{
-							Name: ComponentName,
-							Command: []string{
-								"/bin/bash",
-							},
-							Args:  args,
-							Image: instance.Spec.ContainerImage,
+							Name:    cinderv1beta1.CinderAPIContainerName,
+							Command: command,
+							Args:    args,
+							Image:   instance.Spec.ContainerImage,
 							SecurityContext: &corev1.SecurityContext{
-								RunAsUser: &runAsUser,
+								RunAsUser:  &runAsUser,
+								RunAsGroup: instance.Spec.SecurityContext.RunAsGroup,
 							},
-							Env:            env.MergeEnvs([]corev1.EnvVar{}, envVars),
+							Env:            apiEnv,
 							VolumeMounts:   volumeMounts,
 							Resources:      instance.Spec.Resources,
 							ReadinessProbe: readinessProbe,
 							LivenessProbe:  livenessProbe,
+							StartupProbe:   startupProbe,
+							Lifecycle:      lifecycle,
 						},
 					},
-					Affinity:     cinder.GetPodAffinity(ComponentName),
-					NodeSelector: instance.Spec.NodeSelector,
-					Volumes:      volumes,
+					TerminationGracePeriodSeconds: instance.Spec.TerminationGracePeriodSeconds,
+					Affinity: cinder.AddComponentAntiAffinity(
+						cinder.GetPodAffinity(ComponentName),
+						instance.Spec.ComponentAntiAffinity,
+						[]string{cinderscheduler.ComponentName, cindervolume.ComponentName},
+					),
+					TopologySpreadConstraints: topologySpreadConstraints,
+					NodeSelector:              cinder.GetNodeSelector(instance.Spec.NodeSelector, defaultNodeSelector),
+					Tolerations:               instance.Spec.Tolerations,
+					Volumes:                   volumes,
+					HostAliases:               hostAliases,
 				},
 			},
 		},
 	}
 
+	// the sidecars run next to the managed containers
+	statefulset.Spec.Template.Spec.Containers = append(
+		statefulset.Spec.Template.Spec.Containers, instance.Spec.ExtraContainers...)
+
+	if instance.Spec.UpdateStrategy != nil {
+		statefulset.Spec.UpdateStrategy = *instance.Spec.UpdateStrategy
+	}
+
 	return statefulset, nil
 }
+
+// setProbeConfig - overrides the default timings of the probes with the ones
+// set in the ProbeConfig
+func setProbeConfig(
+	livenessProbe *corev1.Probe,
+	readinessProbe *corev1.Probe,
+	startupProbe *corev1.Probe,
+	probeConfig cinderv1beta1.ProbeConfig,
+) {
+	if probeConfig.LivenessTimeout != nil {
+		livenessProbe.TimeoutSeconds = *probeConfig.LivenessTimeout
+	}
+	if probeConfig.ReadinessTimeout != nil {
+		readinessProbe.TimeoutSeconds = *probeConfig.ReadinessTimeout
+	}
+	if probeConfig.StartupFailureThreshold != nil {
+		startupProbe.FailureThreshold = *probeConfig.StartupFailureThreshold
+	}
+	if probeConfig.StartupPeriodSeconds != nil {
+		startupProbe.PeriodSeconds = *probeConfig.StartupPeriodSeconds
+	}
+	for _, probe := range []*corev1.Probe{livenessProbe, readinessProbe} {
+		if probeConfig.PeriodSeconds != nil {
+			probe.PeriodSeconds = *probeConfig.PeriodSeconds
+		}
+		if probeConfig.InitialDelaySeconds != nil {
+			probe.InitialDelaySeconds = *probeConfig.InitialDelaySeconds
+		}
+		if probeConfig.FailureThreshold != nil {
+			probe.FailureThreshold = *probeConfig.FailureThreshold
+		}
+	}
+}
+
+// GetLogFile - returns the LogFilePath of the cinder-api log, or the LogFile
+// default
+func GetLogFile(instance *cinderv1beta1.CinderAPI) string {
+	if instance.Spec.LogFilePath != "" {
+		return instance.Spec.LogFilePath
+	}
+	return LogFile
+}
+
+// GetLogResources - returns the LogResources of the log container, or small
+// defaults sized for tail rather than the Resources of the API container
+func GetLogResources(instance *cinderv1beta1.CinderAPI) corev1.ResourceRequirements {
+	if instance.Spec.LogResources != nil {
+		return *instance.Spec.LogResources
+	}
+
+	return corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse(DefaultLogCPURequest),
+			corev1.ResourceMemory: resource.MustParse(DefaultLogMemoryRequest),
+		},
+		Limits: corev1.ResourceList{
+			corev1.ResourceMemory: resource.MustParse(DefaultLogMemoryLimit),
+		},
+	}
+}
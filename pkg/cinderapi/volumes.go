@@ -1,6 +1,8 @@
 package cinderapi
 
 import (
+	"path/filepath"
+
 	cinderv1beta1 "github.com/openstack-k8s-operators/cinder-operator/api/v1beta1"
 	"github.com/openstack-k8s-operators/cinder-operator/pkg/cinder"
 	corev1 "k8s.io/api/core/v1"
@@ -32,7 +34,7 @@ func GetVolumes(parentName string, name string, extraVol []cinderv1beta1.CinderE
 }
 
 // GetVolumeMounts - Cinder API VolumeMounts
-func GetVolumeMounts(extraVol []cinderv1beta1.CinderExtraVolMounts) []corev1.VolumeMount {
+func GetVolumeMounts(kollaConfigFile string, logFile string, extraVol []cinderv1beta1.CinderExtraVolMounts) []corev1.VolumeMount {
 	volumeMounts := []corev1.VolumeMount{
 		{
 			Name:      "config-data-custom",
@@ -41,21 +43,21 @@ func GetVolumeMounts(extraVol []cinderv1beta1.CinderExtraVolMounts) []corev1.Vol
 		},
 		{
 			Name:      "config-data",
-			MountPath: "/var/lib/kolla/config_files/config.json",
+			MountPath: kollaConfigFile,
 			SubPath:   "cinder-api-config.json",
 			ReadOnly:  true,
 		},
-		GetLogVolumeMount(),
+		GetLogVolumeMount(logFile),
 	}
 
 	return append(cinder.GetVolumeMounts(false, extraVol, cinder.CinderAPIPropagation), volumeMounts...)
 }
 
-// GetLogVolumeMount - Cinder API LogVolumeMount
-func GetLogVolumeMount() corev1.VolumeMount {
+// GetLogVolumeMount - Cinder API LogVolumeMount of the directory of the logFile
+func GetLogVolumeMount(logFile string) corev1.VolumeMount {
 	return corev1.VolumeMount{
 		Name:      "logs",
-		MountPath: "/var/log/cinder",
+		MountPath: filepath.Dir(logFile),
 		ReadOnly:  false,
 	}
 }
@@ -18,4 +18,10 @@ package cinderbackup
 const (
 	// ComponentName -
 	ComponentName = "cinder-backup"
+
+	// PosixBackupPath - path where the PosixBackupPVC is mounted
+	PosixBackupPath = "/var/lib/cinder/backup"
+
+	// PosixBackupDriver - cinder backup driver storing the backups on a filesystem
+	PosixBackupDriver = "cinder.backup.drivers.posix.PosixBackupDriver"
 )
@@ -38,6 +38,8 @@ func StatefulSet(
 	configHash string,
 	labels map[string]string,
 	annotations map[string]string,
+	hostAliases []corev1.HostAlias,
+	defaultNodeSelector map[string]string,
 ) *appsv1.StatefulSet {
 	trueVar := true
 	rootUser := int64(0)
@@ -89,7 +91,9 @@ func StatefulSet(
 	}
 
 	envVars := map[string]env.Setter{}
+	kollaConfigFile := cinder.GetKollaConfigFile(instance.Spec.KollaConfigFile)
 	envVars["KOLLA_CONFIG_STRATEGY"] = env.SetValue("COPY_ALWAYS")
+	envVars["KOLLA_CONFIG_FILE"] = env.SetValue(kollaConfigFile)
 	envVars["CONFIG_HASH"] = env.SetValue(configHash)
 
 	// Tune glibc for reduced memory usage and fragmentation using single malloc arena for all
@@ -104,7 +108,7 @@ func StatefulSet(
 		cinder.GetOwningCinderName(instance),
 		instance.Name,
 		instance.Spec.ExtraMounts)
-	volumeMounts := GetVolumeMounts(instance.Spec.ExtraMounts)
+	volumeMounts := GetVolumeMounts(kollaConfigFile, instance.Spec.ExtraMounts)
 
 	// Add the CA bundle
 	if instance.Spec.TLS.CaBundleSecretName != "" {
@@ -112,6 +116,24 @@ func StatefulSet(
 		volumeMounts = append(volumeMounts, instance.Spec.TLS.CreateVolumeMounts(nil)...)
 	}
 
+	// add RabbitMQ TLS certs if defined
+	if instance.Spec.TransportTLSSecret != "" {
+		volumes = append(volumes, cinder.GetTransportTLSVolume(instance.Spec.TransportTLSSecret))
+		volumeMounts = append(volumeMounts, cinder.GetTransportTLSVolumeMount())
+	}
+
+	// add Glance API CA if defined
+	if instance.Spec.GlanceCASecret != "" {
+		volumes = append(volumes, cinder.GetGlanceCAVolume(instance.Spec.GlanceCASecret))
+		volumeMounts = append(volumeMounts, cinder.GetGlanceCAVolumeMount())
+	}
+
+	// add the backup target if defined
+	if instance.Spec.PosixBackupPVC != "" {
+		volumes = append(volumes, GetPosixBackupVolume(instance.Spec.PosixBackupPVC))
+		volumeMounts = append(volumeMounts, GetPosixBackupVolumeMount())
+	}
+
 	statefulset := &appsv1.StatefulSet{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      instance.Name,
@@ -126,7 +148,7 @@ func StatefulSet(
 			Template: corev1.PodTemplateSpec{
 				ObjectMeta: metav1.ObjectMeta{
 					Annotations: annotations,
-					Labels:      labels,
+					Labels:      cinder.GetPodLabels(labels, configHash),
 				},
 				Spec: corev1.PodSpec{
 					ServiceAccountName: instance.Spec.ServiceAccount,
@@ -164,8 +186,9 @@ func StatefulSet(
 						},
 					},
 					Affinity:     cinder.GetPodAffinity(ComponentName),
-					NodeSelector: instance.Spec.NodeSelector,
+					NodeSelector: cinder.GetNodeSelector(instance.Spec.NodeSelector, defaultNodeSelector),
 					Volumes:      volumes,
+					HostAliases:  hostAliases,
 				},
 			},
 		},
@@ -26,7 +26,7 @@ func GetVolumes(parentName string, name string, extraVol []cinderv1beta1.CinderE
 }
 
 // GetVolumeMounts - Cinder Backup VolumeMounts
-func GetVolumeMounts(extraVol []cinderv1beta1.CinderExtraVolMounts) []corev1.VolumeMount {
+func GetVolumeMounts(kollaConfigFile string, extraVol []cinderv1beta1.CinderExtraVolMounts) []corev1.VolumeMount {
 	volumeMounts := []corev1.VolumeMount{
 		{
 			Name:      "config-data-custom",
@@ -35,7 +35,7 @@ func GetVolumeMounts(extraVol []cinderv1beta1.CinderExtraVolMounts) []corev1.Vol
 		},
 		{
 			Name:      "config-data",
-			MountPath: "/var/lib/kolla/config_files/config.json",
+			MountPath: kollaConfigFile,
 			SubPath:   "cinder-backup-config.json",
 			ReadOnly:  true,
 		},
@@ -43,3 +43,23 @@ func GetVolumeMounts(extraVol []cinderv1beta1.CinderExtraVolMounts) []corev1.Vol
 
 	return append(cinder.GetVolumeMounts(true, extraVol, cinder.CinderBackupPropagation), volumeMounts...)
 }
+
+// GetPosixBackupVolume - Volume for the PersistentVolumeClaim holding the backups
+func GetPosixBackupVolume(claimName string) corev1.Volume {
+	return corev1.Volume{
+		Name: "posix-backup",
+		VolumeSource: corev1.VolumeSource{
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+				ClaimName: claimName,
+			},
+		},
+	}
+}
+
+// GetPosixBackupVolumeMount - VolumeMount for the PersistentVolumeClaim holding the backups
+func GetPosixBackupVolumeMount() corev1.VolumeMount {
+	return corev1.VolumeMount{
+		Name:      "posix-backup",
+		MountPath: PosixBackupPath,
+	}
+}
@@ -26,7 +26,7 @@ func GetVolumes(parentName string, name string, extraVol []cinderv1beta1.CinderE
 }
 
 // GetVolumeMounts - Cinder Scheduler VolumeMounts
-func GetVolumeMounts(extraVol []cinderv1beta1.CinderExtraVolMounts) []corev1.VolumeMount {
+func GetVolumeMounts(kollaConfigFile string, extraVol []cinderv1beta1.CinderExtraVolMounts) []corev1.VolumeMount {
 	volumeMounts := []corev1.VolumeMount{
 		{
 			Name:      "config-data-custom",
@@ -35,7 +35,7 @@ func GetVolumeMounts(extraVol []cinderv1beta1.CinderExtraVolMounts) []corev1.Vol
 		},
 		{
 			Name:      "config-data",
-			MountPath: "/var/lib/kolla/config_files/config.json",
+			MountPath: kollaConfigFile,
 			SubPath:   "cinder-scheduler-config.json",
 			ReadOnly:  true,
 		},
@@ -0,0 +1,32 @@
+package cindervolume
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// GetReplicationDevices - Renders the replication devices of each backend in
+// the "key1:value1,key2:value2" format expected by the replication_device option
+func GetReplicationDevices(replicationDevices map[string][]map[string]string) map[string][]string {
+	devices := map[string][]string{}
+
+	for backend, targets := range replicationDevices {
+		for _, target := range targets {
+			keys := make([]string, 0, len(target))
+			for key := range target {
+				keys = append(keys, key)
+			}
+			// keep the rendered config, and so the config hash, stable
+			sort.Strings(keys)
+
+			params := make([]string, 0, len(keys))
+			for _, key := range keys {
+				params = append(params, fmt.Sprintf("%s:%s", key, target[key]))
+			}
+			devices[backend] = append(devices[backend], strings.Join(params, ","))
+		}
+	}
+
+	return devices
+}
@@ -30,6 +30,14 @@ import (
 const (
 	// ServiceCommand -
 	ServiceCommand = "/usr/local/bin/kolla_set_configs && /usr/local/bin/kolla_start"
+
+	// PreStopCommand - stops cinder-volume and waits until it completed its
+	// in-flight operations, as bounded by graceful_shutdown_timeout
+	PreStopCommand = "pkill -TERM -f /usr/bin/cinder-volume; while pgrep -f /usr/bin/cinder-volume > /dev/null; do sleep 1; done"
+
+	// GracefulShutdownMargin - seconds added to the GracefulShutdownTimeout for
+	// the termination grace period of the pod
+	GracefulShutdownMargin = 10
 )
 
 // StatefulSet func
@@ -38,6 +46,8 @@ func StatefulSet(
 	configHash string,
 	labels map[string]string,
 	annotations map[string]string,
+	hostAliases []corev1.HostAlias,
+	defaultNodeSelector map[string]string,
 ) *appsv1.StatefulSet {
 	trueVar := true
 	rootUser := int64(0)
@@ -59,8 +69,20 @@ func StatefulSet(
 		InitialDelaySeconds: 5,
 	}
 
+	// The healthcheck server only succeeds when the services of all the
+	// enabled backends are up, so a pod whose backend is down is reported
+	// NotReady well before the liveness probe restarts it.
+	readinessProbe := &corev1.Probe{
+		TimeoutSeconds:      5,
+		PeriodSeconds:       5,
+		InitialDelaySeconds: 5,
+		FailureThreshold:    1,
+	}
+
 	args := []string{"-c"}
 	var probeCommand []string
+	var lifecycle *corev1.Lifecycle
+	var terminationGracePeriod *int64
 	// When debugging the service container will run kolla_set_configs and
 	// sleep forever and the probe container will just sleep forever.
 	if instance.Spec.Debug.Service {
@@ -71,6 +93,7 @@ func StatefulSet(
 			},
 		}
 		startupProbe.Exec = livenessProbe.Exec
+		readinessProbe.Exec = livenessProbe.Exec
 		probeCommand = []string{
 			"/bin/sleep", "infinity",
 		}
@@ -81,15 +104,32 @@ func StatefulSet(
 			Port: intstr.FromInt(8080),
 		}
 		startupProbe.HTTPGet = livenessProbe.HTTPGet
+		readinessProbe.HTTPGet = livenessProbe.HTTPGet
 		probeCommand = []string{
 			"/usr/local/bin/container-scripts/healthcheck.py",
 			"volume",
 			"/etc/cinder/cinder.conf.d",
 		}
+
+		if instance.Spec.GracefulShutdownTimeout != nil {
+			// The service is not PID 1 and doesn't get the SIGTERM of the
+			// pod termination, so ask it to stop and wait for it to drain
+			lifecycle = &corev1.Lifecycle{
+				PreStop: &corev1.LifecycleHandler{
+					Exec: &corev1.ExecAction{
+						Command: []string{"/bin/bash", "-c", PreStopCommand},
+					},
+				},
+			}
+			gracePeriod := int64(*instance.Spec.GracefulShutdownTimeout) + GracefulShutdownMargin
+			terminationGracePeriod = &gracePeriod
+		}
 	}
 
 	envVars := map[string]env.Setter{}
+	kollaConfigFile := cinder.GetKollaConfigFile(instance.Spec.KollaConfigFile)
 	envVars["KOLLA_CONFIG_STRATEGY"] = env.SetValue("COPY_ALWAYS")
+	envVars["KOLLA_CONFIG_FILE"] = env.SetValue(kollaConfigFile)
 	envVars["CONFIG_HASH"] = env.SetValue(configHash)
 
 	// Tune glibc for reduced memory usage and fragmentation using single malloc arena for all
@@ -104,7 +144,7 @@ func StatefulSet(
 		cinder.GetOwningCinderName(instance),
 		instance.Name,
 		instance.Spec.ExtraMounts)
-	volumeMounts := GetVolumeMounts(instance.Name, instance.Spec.ExtraMounts)
+	volumeMounts := GetVolumeMounts(instance.Name, kollaConfigFile, instance.Spec.ExtraMounts)
 
 	// Add the CA bundle
 	if instance.Spec.TLS.CaBundleSecretName != "" {
@@ -112,6 +152,18 @@ func StatefulSet(
 		volumeMounts = append(volumeMounts, instance.Spec.TLS.CreateVolumeMounts(nil)...)
 	}
 
+	// add RabbitMQ TLS certs if defined
+	if instance.Spec.TransportTLSSecret != "" {
+		volumes = append(volumes, cinder.GetTransportTLSVolume(instance.Spec.TransportTLSSecret))
+		volumeMounts = append(volumeMounts, cinder.GetTransportTLSVolumeMount())
+	}
+
+	// add Glance API CA if defined
+	if instance.Spec.GlanceCASecret != "" {
+		volumes = append(volumes, cinder.GetGlanceCAVolume(instance.Spec.GlanceCASecret))
+		volumeMounts = append(volumeMounts, cinder.GetGlanceCAVolumeMount())
+	}
+
 	statefulset := &appsv1.StatefulSet{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      instance.Name,
@@ -126,7 +178,7 @@ func StatefulSet(
 			Template: corev1.PodTemplateSpec{
 				ObjectMeta: metav1.ObjectMeta{
 					Annotations: annotations,
-					Labels:      labels,
+					Labels:      cinder.GetPodLabels(labels, configHash),
 				},
 				Spec: corev1.PodSpec{
 					ServiceAccountName: instance.Spec.ServiceAccount,
@@ -146,11 +198,13 @@ func StatefulSet(
 								RunAsUser:  &rootUser,
 								Privileged: &trueVar,
 							},
-							Env:           env.MergeEnvs([]corev1.EnvVar{}, envVars),
-							VolumeMounts:  volumeMounts,
-							Resources:     instance.Spec.Resources,
-							LivenessProbe: livenessProbe,
-							StartupProbe:  startupProbe,
+							Env:            env.MergeEnvs([]corev1.EnvVar{}, envVars),
+							VolumeMounts:   volumeMounts,
+							Resources:      instance.Spec.Resources,
+							LivenessProbe:  livenessProbe,
+							ReadinessProbe: readinessProbe,
+							StartupProbe:   startupProbe,
+							Lifecycle:      lifecycle,
 						},
 						{
 							Name:    "probe",
@@ -163,9 +217,11 @@ func StatefulSet(
 							VolumeMounts: volumeMounts,
 						},
 					},
-					Affinity:     cinder.GetPodAffinity(ComponentName),
-					NodeSelector: instance.Spec.NodeSelector,
-					Volumes:      volumes,
+					TerminationGracePeriodSeconds: terminationGracePeriod,
+					Affinity:                      cinder.GetPodAffinity(ComponentName),
+					NodeSelector:                  cinder.GetNodeSelector(instance.Spec.NodeSelector, defaultNodeSelector),
+					Volumes:                       volumes,
+					HostAliases:                   hostAliases,
 				},
 			},
 		},
@@ -31,7 +31,7 @@ func GetVolumes(parentName string, name string, extraVol []cinderv1beta1.CinderE
 }
 
 // GetVolumeMounts - Cinder Volume VolumeMounts
-func GetVolumeMounts(name string, extraVol []cinderv1beta1.CinderExtraVolMounts) []corev1.VolumeMount {
+func GetVolumeMounts(name string, kollaConfigFile string, extraVol []cinderv1beta1.CinderExtraVolMounts) []corev1.VolumeMount {
 	volumeVolumeMounts := []corev1.VolumeMount{
 		{
 			Name:      "config-data-custom",
@@ -40,7 +40,7 @@ func GetVolumeMounts(name string, extraVol []cinderv1beta1.CinderExtraVolMounts)
 		},
 		{
 			Name:      "config-data",
-			MountPath: "/var/lib/kolla/config_files/config.json",
+			MountPath: kollaConfigFile,
 			SubPath:   "cinder-volume-config.json",
 			ReadOnly:  true,
 		},
@@ -18,15 +18,18 @@ import (
 
 	"golang.org/x/exp/maps"
 
+	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	corev1 "k8s.io/api/core/v1"
 	k8s_errors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
 	cinderv1 "github.com/openstack-k8s-operators/cinder-operator/api/v1beta1"
+	memcachedv1 "github.com/openstack-k8s-operators/infra-operator/apis/memcached/v1beta1"
 	condition "github.com/openstack-k8s-operators/lib-common/modules/common/condition"
 )
 
@@ -162,6 +165,38 @@ func CreateCinder(name types.NamespacedName, spec map[string]interface{}) client
 	return CreateUnstructured(raw)
 }
 
+// CreateCinderFixture - creates the Cinder CR described by names with the
+// given spec, together with the message bus Secret, the database Service, the
+// Memcached and the KeystoneAPI it depends on. The TransportURL, Memcached,
+// MariaDB account and database and the db sync Job are then simulated ready,
+// so the Cinder controller goes on creating the sub-CRs. The osp-secret has
+// to exist in the namespace already. Everything created here is registered
+// for cleanup.
+func CreateCinderFixture(names CinderTestData, spec map[string]interface{}) {
+	DeferCleanup(th.DeleteInstance, CreateCinder(names.Instance, spec))
+	DeferCleanup(k8sClient.Delete, ctx, CreateCinderMessageBusSecret(names.Instance.Namespace, names.RabbitmqSecretName))
+	DeferCleanup(
+		mariadb.DeleteDBService,
+		mariadb.CreateDBService(
+			names.Instance.Namespace,
+			GetCinder(names.Instance).Spec.DatabaseInstance,
+			corev1.ServiceSpec{
+				Ports: []corev1.ServicePort{{Port: 3306}},
+			},
+		),
+	)
+	infra.SimulateTransportURLReady(names.CinderTransportURL)
+	memcachedSpec := memcachedv1.MemcachedSpec{
+		Replicas: ptr.To(int32(3)),
+	}
+	DeferCleanup(infra.DeleteMemcached, infra.CreateMemcached(names.Instance.Namespace, names.MemcachedInstance, memcachedSpec))
+	infra.SimulateMemcachedReady(names.CinderMemcached)
+	DeferCleanup(keystone.DeleteKeystoneAPI, keystone.CreateKeystoneAPI(names.Instance.Namespace))
+	mariadb.SimulateMariaDBAccountCompleted(names.Instance)
+	mariadb.SimulateMariaDBDatabaseCompleted(names.Instance)
+	th.SimulateJobSuccess(names.CinderDBSync)
+}
+
 func CinderConditionGetter(name types.NamespacedName) condition.Conditions {
 	instance := GetCinder(name)
 	return instance.Status.Conditions
@@ -230,6 +265,19 @@ func GetCinderVolume(name types.NamespacedName) *cinderv1.CinderVolume {
 	return instance
 }
 
+// GetEventReasons - returns the reasons of the events recorded for the object
+func GetEventReasons(g Gomega, name types.NamespacedName) []string {
+	events := &corev1.EventList{}
+	g.Expect(k8sClient.List(ctx, events, client.InNamespace(name.Namespace))).Should(Succeed())
+	reasons := []string{}
+	for _, event := range events.Items {
+		if event.InvolvedObject.Name == name.Name {
+			reasons = append(reasons, event.Reason)
+		}
+	}
+	return reasons
+}
+
 func CinderAPIConditionGetter(name types.NamespacedName) condition.Conditions {
 	instance := GetCinderAPI(name)
 	return instance.Status.Conditions
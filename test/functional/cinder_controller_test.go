@@ -17,17 +17,34 @@ package functional
 
 import (
 	"fmt"
+	"time"
 
+	"github.com/google/uuid"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	. "github.com/openstack-k8s-operators/lib-common/modules/common/test/helpers"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
 	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	k8s_errors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	cinderv1 "github.com/openstack-k8s-operators/cinder-operator/api/v1beta1"
+	cinder "github.com/openstack-k8s-operators/cinder-operator/pkg/cinder"
+	cinderapi "github.com/openstack-k8s-operators/cinder-operator/pkg/cinderapi"
 	memcachedv1 "github.com/openstack-k8s-operators/infra-operator/apis/memcached/v1beta1"
+	keystonev1 "github.com/openstack-k8s-operators/keystone-operator/api/v1beta1"
+	common "github.com/openstack-k8s-operators/lib-common/modules/common"
 	condition "github.com/openstack-k8s-operators/lib-common/modules/common/condition"
 	util "github.com/openstack-k8s-operators/lib-common/modules/common/util"
+	mariadbv1 "github.com/openstack-k8s-operators/mariadb-operator/api/v1beta1"
 )
 
 var _ = Describe("Cinder controller", func() {
@@ -59,6 +76,11 @@ var _ = Describe("Cinder controller", func() {
 				corev1.ConditionUnknown,
 			)
 		})
+		It("reports why it is requeued", func() {
+			Eventually(func(g Gomega) {
+				g.Expect(GetCinder(cinderTest.Instance).Status.RequeueReason).ToNot(BeEmpty())
+			}, timeout, interval).Should(Succeed())
+		})
 		It("should have the Spec fields initialized", func() {
 			Cinder := GetCinder(cinderTest.Instance)
 			Expect(Cinder.Spec.DatabaseInstance).Should(Equal("openstack"))
@@ -176,6 +198,68 @@ var _ = Describe("Cinder controller", func() {
 				corev1.ConditionFalse,
 			)
 		})
+		It("reruns the db sync when the CinderAPI image changes", func() {
+			mariadb.SimulateMariaDBAccountCompleted(cinderTest.Instance)
+			mariadb.SimulateMariaDBDatabaseCompleted(cinderTest.Instance)
+			th.SimulateJobSuccess(cinderTest.CinderDBSync)
+			th.ExpectCondition(
+				cinderName,
+				ConditionGetterFunc(CinderConditionGetter),
+				condition.DBSyncReadyCondition,
+				corev1.ConditionTrue,
+			)
+			dbSyncHash := GetCinder(cinderTest.Instance).Status.Hash[cinderv1.DbSyncHash]
+
+			newImage := "quay.io/podified-antelope-centos9/openstack-cinder-api:new-release"
+			Eventually(func(g Gomega) {
+				cinder := GetCinder(cinderTest.Instance)
+				cinder.Spec.CinderAPI.ContainerImage = newImage
+				g.Expect(k8sClient.Update(ctx, cinder)).To(Succeed())
+			}, timeout, interval).Should(Succeed())
+
+			Eventually(func(g Gomega) {
+				job := th.GetJob(cinderTest.CinderDBSync)
+				g.Expect(job.Spec.Template.Spec.Containers[0].Image).To(Equal(newImage))
+			}, timeout, interval).Should(Succeed())
+
+			// the new image changes the job hash, which is recorded once the db sync succeeded
+			th.SimulateJobSuccess(cinderTest.CinderDBSync)
+			Eventually(func(g Gomega) {
+				g.Expect(GetCinder(cinderTest.Instance).Status.Hash).To(HaveKey(cinderv1.DbSyncHash))
+				g.Expect(GetCinder(cinderTest.Instance).Status.Hash[cinderv1.DbSyncHash]).NotTo(Equal(dbSyncHash))
+			}, timeout, interval).Should(Succeed())
+		})
+		It("reruns the db sync for a new CinderAPI image after a failed one", func() {
+			mariadb.SimulateMariaDBAccountCompleted(cinderTest.Instance)
+			mariadb.SimulateMariaDBDatabaseCompleted(cinderTest.Instance)
+			th.SimulateJobFailure(cinderTest.CinderDBSync)
+			th.ExpectCondition(
+				cinderName,
+				ConditionGetterFunc(CinderConditionGetter),
+				condition.DBSyncReadyCondition,
+				corev1.ConditionFalse,
+			)
+
+			newImage := "quay.io/podified-antelope-centos9/openstack-cinder-api:fixed-release"
+			Eventually(func(g Gomega) {
+				cinder := GetCinder(cinderTest.Instance)
+				cinder.Spec.CinderAPI.ContainerImage = newImage
+				g.Expect(k8sClient.Update(ctx, cinder)).To(Succeed())
+			}, timeout, interval).Should(Succeed())
+
+			Eventually(func(g Gomega) {
+				job := th.GetJob(cinderTest.CinderDBSync)
+				g.Expect(job.Spec.Template.Spec.Containers[0].Image).To(Equal(newImage))
+			}, timeout, interval).Should(Succeed())
+
+			th.SimulateJobSuccess(cinderTest.CinderDBSync)
+			th.ExpectCondition(
+				cinderName,
+				ConditionGetterFunc(CinderConditionGetter),
+				condition.DBSyncReadyCondition,
+				corev1.ConditionTrue,
+			)
+		})
 		It("Should fail if db-sync job fails when DB is Created", func() {
 			mariadb.SimulateMariaDBAccountCompleted(cinderTest.Instance)
 			mariadb.SimulateMariaDBDatabaseCompleted(cinderTest.Instance)
@@ -229,6 +313,35 @@ var _ = Describe("Cinder controller", func() {
 				return th.GetSecret(cinderTest.CinderConfigScripts)
 			}, timeout, interval).ShouldNot(BeNil())
 		})
+		It("runs the default number of API workers", func() {
+			keystoneAPI := keystone.CreateKeystoneAPI(cinderTest.Instance.Namespace)
+			DeferCleanup(keystone.DeleteKeystoneAPI, keystoneAPI)
+			Eventually(func(g Gomega) {
+				configData := th.GetSecret(cinderTest.CinderConfigSecret)
+				g.Expect(string(configData.Data["00-global-defaults.conf"])).Should(
+					ContainSubstring("osapi_volume_workers = 4"))
+				g.Expect(string(configData.Data["10-cinder_wsgi.conf"])).Should(
+					ContainSubstring("processes=4 threads=1"))
+			}, timeout, interval).Should(Succeed())
+		})
+	})
+	When("Cinder CR instance is built with CinderAPI workers", func() {
+		BeforeEach(func() {
+			apiSpec := GetDefaultCinderAPISpec()
+			apiSpec["apiWorkers"] = 8
+			spec := GetDefaultCinderSpec()
+			spec["cinderAPI"] = apiSpec
+			CreateCinderFixture(cinderTest, spec)
+		})
+		It("runs the configured number of API workers", func() {
+			Eventually(func(g Gomega) {
+				configData := th.GetSecret(cinderTest.CinderConfigSecret)
+				g.Expect(string(configData.Data["00-global-defaults.conf"])).Should(
+					ContainSubstring("osapi_volume_workers = 8"))
+				g.Expect(string(configData.Data["10-cinder_wsgi.conf"])).Should(
+					ContainSubstring("processes=8 threads=1"))
+			}, timeout, interval).Should(Succeed())
+		})
 	})
 	When("Cinder CR is created without container images defined", func() {
 		BeforeEach(func() {
@@ -285,6 +398,236 @@ var _ = Describe("Cinder controller", func() {
 			th.AssertServiceExists(cinderTest.CinderServicePublic)
 			th.AssertServiceExists(cinderTest.CinderServiceInternal)
 		})
+		It("creates the metrics service owned by the CinderAPI", func() {
+			metricsService := types.NamespacedName{Namespace: cinderTest.Instance.Namespace, Name: cinderTest.CinderAPI.Name + "-metrics"}
+			Eventually(func(g Gomega) {
+				svc := th.GetService(metricsService)
+				g.Expect(svc.Spec.Type).To(Equal(corev1.ServiceTypeClusterIP))
+				g.Expect(svc.Spec.Ports).To(HaveLen(1))
+				g.Expect(svc.Spec.Ports[0].Name).To(Equal("metrics"))
+				g.Expect(svc.Spec.Ports[0].Port).To(Equal(int32(9102)))
+				g.Expect(svc.Spec.Selector).To(HaveKeyWithValue("component", "cinder-api"))
+				g.Expect(svc.Labels).To(HaveKeyWithValue("endpoint", "metrics"))
+				g.Expect(svc.OwnerReferences).To(HaveLen(1))
+				g.Expect(svc.OwnerReferences[0].Kind).To(Equal("CinderAPI"))
+				g.Expect(svc.OwnerReferences[0].Name).To(Equal(cinderTest.CinderAPI.Name))
+
+				g.Expect(GetCinderAPI(cinderTest.CinderAPI).Status.MetricsEndpoint).To(Equal(
+					fmt.Sprintf("http://%s-metrics.%s.svc:9102/metrics", cinderTest.CinderAPI.Name, cinderTest.Instance.Namespace)))
+			}, timeout, interval).Should(Succeed())
+		})
+		It("reports the V3 endpoints as exposed", func() {
+			th.ExpectCondition(
+				cinderTest.CinderAPI,
+				ConditionGetterFunc(CinderAPIConditionGetter),
+				cinderv1.CinderV3EndpointReadyCondition,
+				corev1.ConditionTrue,
+			)
+		})
+		It("is not Ready while the keystone service is not registered", func() {
+			th.ExpectConditionWithDetails(
+				cinderTest.CinderAPI,
+				ConditionGetterFunc(CinderAPIConditionGetter),
+				condition.KeystoneServiceReadyCondition,
+				corev1.ConditionFalse,
+				condition.RequestedReason,
+				fmt.Sprintf(cinderv1.KeystoneServiceNotRegisteredMessage, "cinderv3"),
+			)
+			Expect(GetCinderAPI(cinderTest.CinderAPI).IsReady()).To(BeFalse())
+		})
+		It("reports the keystone service Ready once it is registered", func() {
+			Eventually(func(g Gomega) {
+				ksSvc := keystone.GetKeystoneService(cinderTest.CinderKeystoneService)
+				ksSvc.Status.ServiceID = "cinderv3-id"
+				g.Expect(k8sClient.Status().Update(ctx, ksSvc)).To(Succeed())
+			}, timeout, interval).Should(Succeed())
+			th.ExpectCondition(
+				cinderTest.CinderAPI,
+				ConditionGetterFunc(CinderAPIConditionGetter),
+				condition.KeystoneServiceReadyCondition,
+				corev1.ConditionTrue,
+			)
+			Eventually(func(g Gomega) {
+				g.Expect(GetCinderAPI(cinderTest.CinderAPI).Status.ServiceIDs).To(
+					HaveKeyWithValue("cinderv3", "cinderv3-id"))
+			}, timeout, interval).Should(Succeed())
+		})
+		It("passes the keystone timeout to the CinderAPI", func() {
+			Eventually(func(g Gomega) {
+				g.Expect(GetCinderAPI(cinderTest.CinderAPI).Spec.KeystoneTimeout).To(Equal(10))
+			}, timeout, interval).Should(Succeed())
+
+			Eventually(func(g Gomega) {
+				cinder := GetCinder(cinderTest.Instance)
+				cinder.Spec.CinderAPI.KeystoneTimeout = 30
+				g.Expect(k8sClient.Update(ctx, cinder)).To(Succeed())
+			}, timeout, interval).Should(Succeed())
+			Eventually(func(g Gomega) {
+				g.Expect(GetCinderAPI(cinderTest.CinderAPI).Spec.KeystoneTimeout).To(Equal(30))
+			}, timeout, interval).Should(Succeed())
+		})
+		It("summarizes the CinderAPI state", func() {
+			Eventually(func(g Gomega) {
+				g.Expect(GetCinderAPI(cinderTest.CinderAPI).Status.Summary).ToNot(BeEmpty())
+			}, timeout, interval).Should(Succeed())
+		})
+		It("labels the pods with the config hash", func() {
+			Eventually(func(g Gomega) {
+				ss := th.GetStatefulSet(cinderTest.CinderScheduler)
+				configHash := GetEnvVarValue(ss.Spec.Template.Spec.Containers[0].Env, "CONFIG_HASH", "")
+				g.Expect(configHash).NotTo(BeEmpty())
+				g.Expect(ss.Spec.Template.Labels).To(HaveKeyWithValue("cinder.openstack.org/config-hash", configHash[:10]))
+				g.Expect(ss.Spec.Selector.MatchLabels).NotTo(HaveKey("cinder.openstack.org/config-hash"))
+			}, timeout, interval).Should(Succeed())
+		})
+		It("points kolla to the default config file", func() {
+			Eventually(func(g Gomega) {
+				ss := th.GetStatefulSet(cinderTest.CinderScheduler)
+				container := ss.Spec.Template.Spec.Containers[0]
+				g.Expect(GetEnvVarValue(container.Env, "KOLLA_CONFIG_FILE", "")).To(Equal("/var/lib/kolla/config_files/config.json"))
+				g.Expect(container.VolumeMounts).To(ContainElement(HaveField("MountPath", "/var/lib/kolla/config_files/config.json")))
+			}, timeout, interval).Should(Succeed())
+		})
+		It("reconciles when the OpenStack secret is rotated", func() {
+			var inputHash string
+			Eventually(func(g Gomega) {
+				inputHash = GetCinder(cinderTest.Instance).Status.Hash[common.InputHashName]
+				g.Expect(inputHash).ToNot(BeEmpty())
+			}, timeout, interval).Should(Succeed())
+
+			Eventually(func(g Gomega) {
+				ospSecret := th.GetSecret(types.NamespacedName{Namespace: namespace, Name: SecretName})
+				ospSecret.Data["CinderPassword"] = []byte("rotated-password")
+				g.Expect(k8sClient.Update(ctx, &ospSecret)).To(Succeed())
+			}, timeout, interval).Should(Succeed())
+
+			Eventually(func(g Gomega) {
+				g.Expect(GetCinder(cinderTest.Instance).Status.Hash[common.InputHashName]).ToNot(Equal(inputHash))
+			}, timeout, interval).Should(Succeed())
+		})
+		It("records the hash of each input when the OpenStack secret is rotated", func() {
+			secretKey := cinderv1.InputHashPrefix + "secret-" + SecretName
+			scriptsKey := cinderv1.InputHashPrefix + cinderTest.Instance.Name + "-scripts"
+			var hashes map[string]string
+			Eventually(func(g Gomega) {
+				hashes = GetCinder(cinderTest.Instance).Status.Hash
+				g.Expect(hashes).To(HaveKey(secretKey))
+				g.Expect(hashes).To(HaveKey(scriptsKey))
+			}, timeout, interval).Should(Succeed())
+
+			Eventually(func(g Gomega) {
+				ospSecret := th.GetSecret(types.NamespacedName{Namespace: namespace, Name: SecretName})
+				ospSecret.Data["CinderPassword"] = []byte("rotated-password")
+				g.Expect(k8sClient.Update(ctx, &ospSecret)).To(Succeed())
+			}, timeout, interval).Should(Succeed())
+
+			Eventually(func(g Gomega) {
+				newHashes := GetCinder(cinderTest.Instance).Status.Hash
+				g.Expect(newHashes[secretKey]).ToNot(Equal(hashes[secretKey]))
+				g.Expect(newHashes[scriptsKey]).To(Equal(hashes[scriptsKey]))
+			}, timeout, interval).Should(Succeed())
+		})
+		It("restarts the CinderAPI pods when the OpenStack secret is rotated", func() {
+			secretKey := cinderv1.InputHashPrefix + "secret-" + SecretName
+			var configHash, secretHash string
+			Eventually(func(g Gomega) {
+				configHash = GetEnvVarValue(
+					th.GetStatefulSet(cinderTest.CinderAPI).Spec.Template.Spec.Containers[1].Env, "CONFIG_HASH", "")
+				g.Expect(configHash).ToNot(BeEmpty())
+				secretHash = GetCinderAPI(cinderTest.CinderAPI).Status.Hash[secretKey]
+				g.Expect(secretHash).ToNot(BeEmpty())
+			}, timeout, interval).Should(Succeed())
+
+			Eventually(func(g Gomega) {
+				ospSecret := th.GetSecret(types.NamespacedName{Namespace: namespace, Name: SecretName})
+				ospSecret.Data["CinderPassword"] = []byte("rotated-password")
+				g.Expect(k8sClient.Update(ctx, &ospSecret)).To(Succeed())
+			}, timeout, interval).Should(Succeed())
+
+			Eventually(func(g Gomega) {
+				g.Expect(GetCinderAPI(cinderTest.CinderAPI).Status.Hash[secretKey]).ToNot(Equal(secretHash))
+				g.Expect(GetEnvVarValue(
+					th.GetStatefulSet(cinderTest.CinderAPI).Spec.Template.Spec.Containers[1].Env, "CONFIG_HASH", "")).ToNot(Equal(configHash))
+			}, timeout, interval).Should(Succeed())
+		})
+		It("names only the rotated OpenStack secret in the InputHashChanged event", func() {
+			Eventually(func(g Gomega) {
+				g.Expect(GetCinderAPI(cinderTest.CinderAPI).Status.Hash).To(
+					HaveKey(cinderv1.InputHashPrefix + "secret-" + SecretName))
+			}, timeout, interval).Should(Succeed())
+
+			Eventually(func(g Gomega) {
+				ospSecret := th.GetSecret(types.NamespacedName{Namespace: namespace, Name: SecretName})
+				ospSecret.Data["CinderPassword"] = []byte("rotated-password")
+				g.Expect(k8sClient.Update(ctx, &ospSecret)).To(Succeed())
+			}, timeout, interval).Should(Succeed())
+
+			Eventually(func(g Gomega) {
+				events := &corev1.EventList{}
+				g.Expect(k8sClient.List(ctx, events, client.InNamespace(namespace))).Should(Succeed())
+				messages := []string{}
+				for _, event := range events.Items {
+					if event.InvolvedObject.Name == cinderTest.CinderAPI.Name && event.Reason == "InputHashChanged" {
+						messages = append(messages, event.Message)
+					}
+				}
+				g.Expect(messages).To(ContainElement(ContainSubstring("secret-" + SecretName)))
+				// the unchanged inputs are not reported
+				g.Expect(messages).ToNot(ContainElement(ContainSubstring(cinderTest.RabbitmqSecretName)))
+			}, timeout, interval).Should(Succeed())
+		})
+		It("keeps the CinderAPI pods when the OpenStack secret is unchanged", func() {
+			var configHash string
+			Eventually(func(g Gomega) {
+				configHash = GetEnvVarValue(
+					th.GetStatefulSet(cinderTest.CinderAPI).Spec.Template.Spec.Containers[1].Env, "CONFIG_HASH", "")
+				g.Expect(configHash).ToNot(BeEmpty())
+			}, timeout, interval).Should(Succeed())
+
+			Consistently(func(g Gomega) {
+				g.Expect(GetEnvVarValue(
+					th.GetStatefulSet(cinderTest.CinderAPI).Spec.Template.Spec.Containers[1].Env, "CONFIG_HASH", "")).To(Equal(configHash))
+			}, timeout, interval).Should(Succeed())
+		})
+		It("advertises the public endpoint in the API config", func() {
+			Eventually(func(g Gomega) {
+				configData := th.GetSecret(types.NamespacedName{
+					Namespace: cinderTest.CinderAPI.Namespace,
+					Name:      fmt.Sprintf("%s-config-data", cinderTest.CinderAPI.Name),
+				})
+				g.Expect(configData).ShouldNot(BeNil())
+				conf := string(configData.Data["01-service-defaults.conf"])
+				publicEndpoint := fmt.Sprintf("http://cinder-public.%s.svc:8776", namespace)
+				g.Expect(conf).Should(ContainSubstring("public_endpoint = " + publicEndpoint))
+				g.Expect(conf).Should(ContainSubstring("osapi_volume_base_URL = " + publicEndpoint))
+			}, timeout, interval).Should(Succeed())
+		})
+		It("listens on the default API port", func() {
+			Eventually(func(g Gomega) {
+				configData := th.GetSecret(types.NamespacedName{
+					Namespace: cinderTest.Instance.Namespace,
+					Name:      fmt.Sprintf("%s-config-data", cinderTest.Instance.Name),
+				})
+				g.Expect(string(configData.Data["httpd.conf"])).To(ContainSubstring("Listen 8776"))
+				g.Expect(string(configData.Data["10-cinder_wsgi.conf"])).To(ContainSubstring("<VirtualHost *:8776>"))
+			}, timeout, interval).Should(Succeed())
+		})
+		It("streams the default log file", func() {
+			Eventually(func(g Gomega) {
+				ss := th.GetStatefulSet(cinderTest.CinderAPI)
+				logContainer := ss.Spec.Template.Spec.Containers[0]
+				g.Expect(logContainer.Args).To(HaveLen(5))
+				g.Expect(logContainer.Args[4]).To(Equal(cinderapi.LogFile))
+				g.Expect(logContainer.VolumeMounts).To(ContainElement(
+					HaveField("MountPath", "/var/log/cinder")))
+				configData := th.GetSecret(types.NamespacedName{
+					Namespace: cinderTest.CinderAPI.Namespace,
+					Name:      fmt.Sprintf("%s-config-data", cinderTest.CinderAPI.Name),
+				})
+				g.Expect(string(configData.Data["01-service-defaults.conf"])).To(
+					ContainSubstring("log_file = " + cinderapi.LogFile))
+			}, timeout, interval).Should(Succeed())
+		})
 	})
 	When("Cinder CR instance is deleted", func() {
 		BeforeEach(func() {
@@ -319,6 +662,55 @@ var _ = Describe("Cinder controller", func() {
 			mDB = mariadb.GetMariaDBDatabase(cinderTest.Instance)
 			Expect(mDB.Finalizers).NotTo(ContainElement("Cinder"))
 		})
+		It("preserves the Cinder DB by default", func() {
+			keystone.SimulateKeystoneServiceReady(cinderTest.CinderKeystoneService)
+
+			cinder := GetCinder(cinderTest.Instance)
+			Expect(*cinder.Spec.PreserveDatabase).To(BeTrue())
+			mDB := mariadb.GetMariaDBDatabase(cinderTest.Instance)
+			Expect(mDB.OwnerReferences).To(ContainElement(HaveField("UID", cinder.UID)))
+
+			th.DeleteInstance(cinder)
+
+			mDB = mariadb.GetMariaDBDatabase(cinderTest.Instance)
+			Expect(mDB.DeletionTimestamp).To(BeNil())
+			Expect(mDB.OwnerReferences).NotTo(ContainElement(HaveField("UID", cinder.UID)))
+		})
+	})
+	When("Cinder CR instance with preserveDatabase false is deleted", func() {
+		BeforeEach(func() {
+			spec := GetDefaultCinderSpec()
+			spec["preserveDatabase"] = false
+			CreateCinderFixture(cinderTest, spec)
+			keystone.SimulateKeystoneServiceReady(cinderTest.CinderKeystoneService)
+		})
+		It("drops the Cinder DB", func() {
+			th.DeleteInstance(GetCinder(cinderTest.Instance))
+
+			mariadb.AssertMariaDBDatabaseDoesNotExist(cinderTest.Instance)
+		})
+		It("keeps the Cinder DB shared with another service", func() {
+			mDB := mariadb.GetMariaDBDatabase(cinderTest.Instance)
+			sibling := &mariadbv1.MariaDBDatabase{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "sibling-" + mDB.Name,
+					Namespace: mDB.Namespace,
+					Labels:    map[string]string{"dbName": mDB.Labels["dbName"]},
+				},
+				Spec: mariadbv1.MariaDBDatabaseSpec{
+					Name: mDB.Spec.Name,
+				},
+			}
+			Expect(k8sClient.Create(ctx, sibling)).To(Succeed())
+			DeferCleanup(th.DeleteInstance, sibling)
+
+			cinder := GetCinder(cinderTest.Instance)
+			th.DeleteInstance(cinder)
+
+			mDB = mariadb.GetMariaDBDatabase(cinderTest.Instance)
+			Expect(mDB.DeletionTimestamp).To(BeNil())
+			Expect(mDB.OwnerReferences).NotTo(ContainElement(HaveField("UID", cinder.UID)))
+		})
 	})
 	When("Cinder CR instance is built with NAD", func() {
 		BeforeEach(func() {
@@ -439,60 +831,1446 @@ var _ = Describe("Cinder controller", func() {
 			Expect(endpoints).To(HaveKeyWithValue("internal", "http://cinder-internal."+cinder.Namespace+".svc:8776/v3"))
 		})
 	})
-	When("A Cinder with TLS is created", func() {
-		BeforeEach(func() {
-			DeferCleanup(th.DeleteInstance, CreateCinder(cinderTest.Instance, GetTLSCinderSpec()))
-			DeferCleanup(k8sClient.Delete, ctx, CreateCinderMessageBusSecret(cinderTest.Instance.Namespace, cinderTest.RabbitmqSecretName))
-			DeferCleanup(th.DeleteInstance, CreateCinderAPI(cinderTest.Instance, GetDefaultCinderAPISpec()))
-			DeferCleanup(th.DeleteInstance, CreateCinderScheduler(cinderTest.Instance, GetDefaultCinderSchedulerSpec()))
-			DeferCleanup(th.DeleteInstance, CreateCinderVolume(cinderTest.Instance, GetDefaultCinderVolumeSpec()))
-			DeferCleanup(
-				mariadb.DeleteDBService,
-				mariadb.CreateDBService(
-					cinderTest.Instance.Namespace,
-					GetCinder(cinderName).Spec.DatabaseInstance,
-					corev1.ServiceSpec{
-						Ports: []corev1.ServicePort{{Port: 3306}},
+	When("Cinder CRs in two namespaces are built with a HostSuffix", func() {
+		var otherCinderTest CinderTestData
+
+		hostSuffixSpec := func(suffix string) map[string]interface{} {
+			return map[string]interface{}{
+				"secret":              SecretName,
+				"databaseInstance":    "openstack",
+				"rabbitMqClusterName": "rabbitmq",
+				"hostSuffix":          suffix,
+				"cinderAPI":           GetDefaultCinderAPISpec(),
+				"cinderScheduler":     GetDefaultCinderSchedulerSpec(),
+				"cinderVolumes": map[string]interface{}{
+					"volume1": map[string]interface{}{
+						"containerImage": cinderv1.CinderVolumeContainerImage,
 					},
-				),
-			)
-			infra.SimulateTransportURLReady(cinderTest.CinderTransportURL)
-			DeferCleanup(infra.DeleteMemcached, infra.CreateMemcached(namespace, cinderTest.MemcachedInstance, memcachedSpec))
-			infra.SimulateMemcachedReady(cinderTest.CinderMemcached)
-			DeferCleanup(keystone.DeleteKeystoneAPI, keystone.CreateKeystoneAPI(cinderTest.Instance.Namespace))
-			mariadb.SimulateMariaDBAccountCompleted(cinderTest.Instance)
-			mariadb.SimulateMariaDBDatabaseCompleted(cinderTest.Instance)
-			th.SimulateJobSuccess(cinderTest.CinderDBSync)
+				},
+			}
+		}
+		volumeHost := func(names CinderTestData) string {
+			conf := ""
+			Eventually(func(g Gomega) {
+				configData := th.GetSecret(types.NamespacedName{
+					Namespace: names.CinderVolumes[0].Namespace,
+					Name:      fmt.Sprintf("%s-config-data", names.CinderVolumes[0].Name),
+				})
+				g.Expect(configData).ShouldNot(BeNil())
+				conf = string(configData.Data["01-service-defaults.conf"])
+				g.Expect(conf).Should(ContainSubstring("host = "))
+			}, timeout, interval).Should(Succeed())
+			return conf
+		}
+
+		BeforeEach(func() {
+			otherNamespace := uuid.New().String()
+			th.CreateNamespace(otherNamespace)
+			DeferCleanup(th.DeleteNamespace, otherNamespace)
+			otherCinderTest = GetCinderTestData(types.NamespacedName{
+				Namespace: otherNamespace,
+				Name:      cinderName.Name,
+			})
+			DeferCleanup(k8sClient.Delete, ctx, CreateCinderSecret(otherNamespace, SecretName))
+
+			for _, names := range []CinderTestData{cinderTest, otherCinderTest} {
+				CreateCinderFixture(names, hostSuffixSpec(names.Instance.Namespace))
+				keystone.SimulateKeystoneServiceReady(names.CinderKeystoneService)
+				keystone.SimulateKeystoneEndpointReady(names.CinderKeystoneEndpoint)
+			}
 		})
+		It("renders a namespace unique host for each cinder-volume", func() {
+			Expect(GetCinderVolume(cinderTest.CinderVolumes[0]).Spec.HostSuffix).To(Equal(namespace))
+			Expect(GetCinderVolume(otherCinderTest.CinderVolumes[0]).Spec.HostSuffix).To(Equal(otherCinderTest.Instance.Namespace))
 
-		It("reports that the CA secret is missing", func() {
-			th.ExpectConditionWithDetails(
-				cinderTest.CinderAPI,
-				ConditionGetterFunc(CinderAPIConditionGetter),
-				condition.TLSInputReadyCondition,
-				corev1.ConditionFalse,
-				condition.ErrorReason,
-				fmt.Sprintf("TLSInput error occured in TLS sources Secret %s/combined-ca-bundle not found", namespace),
-			)
+			// Both CinderVolumes share the same name, only the suffix keeps the
+			// rendered hosts apart.
+			Expect(otherCinderTest.CinderVolumes[0].Name).To(Equal(cinderTest.CinderVolumes[0].Name))
+
+			conf := volumeHost(cinderTest)
+			Expect(conf).Should(ContainSubstring(
+				fmt.Sprintf("host = %s-0.%s", cinderTest.CinderVolumes[0].Name, namespace)))
+			Expect(conf).ShouldNot(ContainSubstring(otherCinderTest.Instance.Namespace))
 
+			otherConf := volumeHost(otherCinderTest)
+			Expect(otherConf).Should(ContainSubstring(
+				fmt.Sprintf("host = %s-0.%s", otherCinderTest.CinderVolumes[0].Name, otherCinderTest.Instance.Namespace)))
+			Expect(otherConf).ShouldNot(ContainSubstring(namespace))
+		})
+	})
+	When("Cinder CR instance is built with OrderedDeployment", func() {
+		BeforeEach(func() {
+			rawSpec := map[string]interface{}{
+				"secret":              SecretName,
+				"databaseInstance":    "openstack",
+				"rabbitMqClusterName": "rabbitmq",
+				"orderedDeployment":   true,
+				"cinderAPI":           GetDefaultCinderAPISpec(),
+				"cinderScheduler":     GetDefaultCinderSchedulerSpec(),
+				"cinderVolumes": map[string]interface{}{
+					"volume1": map[string]interface{}{
+						"containerImage": cinderv1.CinderVolumeContainerImage,
+					},
+				},
+			}
+			CreateCinderFixture(cinderTest, rawSpec)
+		})
+		It("holds the CinderVolume until CinderAPI and CinderScheduler are Ready", func() {
+			CinderAPIExists(cinderTest.CinderAPI)
+			CinderSchedulerExists(cinderTest.CinderScheduler)
+			Eventually(func(g Gomega) {
+				g.Expect(GetCinder(cinderTest.Instance).Status.DeploymentWaitingOn).To(HaveKeyWithValue(
+					"cinderVolume", []string{"cinderAPI", "cinderScheduler"}))
+			}, timeout, interval).Should(Succeed())
+			CinderVolumeNotExists(cinderTest.CinderVolumes[0])
 			th.ExpectConditionWithDetails(
-				cinderTest.CinderScheduler,
-				ConditionGetterFunc(CinderSchedulerConditionGetter),
-				condition.TLSInputReadyCondition,
+				cinderTest.Instance,
+				ConditionGetterFunc(CinderConditionGetter),
+				cinderv1.CinderVolumeReadyCondition,
 				corev1.ConditionFalse,
-				condition.ErrorReason,
-				fmt.Sprintf("TLSInput error occured in TLS sources Secret %s/combined-ca-bundle not found", namespace),
+				condition.RequestedReason,
+				fmt.Sprintf(cinderv1.DeploymentWaitingOnMessage, "cinderAPI, cinderScheduler"),
 			)
 		})
-
-		It("reports that the internal cert secret is missing", func() {
-			DeferCleanup(k8sClient.Delete, ctx, th.CreateCABundleSecret(cinderTest.CABundleSecret))
-			th.ExpectConditionWithDetails(
-				cinderTest.CinderAPI,
-				ConditionGetterFunc(CinderAPIConditionGetter),
-				condition.TLSInputReadyCondition,
-				corev1.ConditionFalse,
-				condition.ErrorReason,
+	})
+	When("Cinder CR instance is built with per endpoint URL overrides", func() {
+		BeforeEach(func() {
+			apiSpec := GetDefaultCinderAPISpec()
+			apiSpec["override"] = map[string]interface{}{
+				"service": map[string]interface{}{
+					"internal": map[string]interface{}{
+						"endpointURL": "http://cinder.internal.example.com",
+					},
+					"public": map[string]interface{}{
+						"endpointURL": "https://cinder.example.com",
+					},
+				},
+			}
+			rawSpec := map[string]interface{}{
+				"secret":              SecretName,
+				"databaseInstance":    "openstack",
+				"rabbitMqClusterName": "rabbitmq",
+				"cinderAPI":           apiSpec,
+				"cinderScheduler":     GetDefaultCinderSchedulerSpec(),
+			}
+			CreateCinderFixture(cinderTest, rawSpec)
+		})
+		It("advertises a different hostname for each endpoint", func() {
+			Eventually(func(g Gomega) {
+				endpoints := GetCinderAPI(cinderTest.CinderAPI).Status.APIEndpoints["cinderv3"]
+				g.Expect(endpoints).To(HaveKeyWithValue("internal", "http://cinder.internal.example.com/v3"))
+				g.Expect(endpoints).To(HaveKeyWithValue("public", "https://cinder.example.com/v3"))
+			}, timeout, interval).Should(Succeed())
+		})
+	})
+	When("Cinder CR instance is built with an internal only CinderAPI", func() {
+		BeforeEach(func() {
+			apiSpec := GetDefaultCinderAPISpec()
+			apiSpec["exposePublic"] = false
+			spec := GetDefaultCinderSpec()
+			spec["cinderAPI"] = apiSpec
+			CreateCinderFixture(cinderTest, spec)
+			keystone.SimulateKeystoneServiceReady(cinderTest.CinderKeystoneService)
+		})
+		It("only creates the internal service", func() {
+			th.AssertServiceExists(cinderTest.CinderServiceInternal)
+			th.AssertServiceDoesNotExist(cinderTest.CinderServicePublic)
+		})
+		It("only registers the internal endpoint", func() {
+			Eventually(func(g Gomega) {
+				endpoints := GetCinderAPI(cinderTest.CinderAPI).Status.APIEndpoints["cinderv3"]
+				g.Expect(endpoints).To(HaveKey("internal"))
+				g.Expect(endpoints).ToNot(HaveKey("public"))
+			}, timeout, interval).Should(Succeed())
+			Eventually(func(g Gomega) {
+				endpoints := keystone.GetKeystoneEndpoint(cinderTest.CinderKeystoneEndpoint).Spec.Endpoints
+				g.Expect(endpoints).To(HaveKey("internal"))
+				g.Expect(endpoints).ToNot(HaveKey("public"))
+			}, timeout, interval).Should(Succeed())
+		})
+		It("removes the public service once exposed again and hidden", func() {
+			Eventually(func(g Gomega) {
+				cinder := GetCinder(cinderTest.Instance)
+				cinder.Spec.CinderAPI.ExposePublic = ptr.To(true)
+				g.Expect(k8sClient.Update(ctx, cinder)).To(Succeed())
+			}, timeout, interval).Should(Succeed())
+			th.AssertServiceExists(cinderTest.CinderServicePublic)
+
+			Eventually(func(g Gomega) {
+				cinder := GetCinder(cinderTest.Instance)
+				cinder.Spec.CinderAPI.ExposePublic = ptr.To(false)
+				g.Expect(k8sClient.Update(ctx, cinder)).To(Succeed())
+			}, timeout, interval).Should(Succeed())
+			th.AssertServiceDoesNotExist(cinderTest.CinderServicePublic)
+		})
+	})
+	When("Cinder CR instance is built with a hostAliases ConfigMap", func() {
+		BeforeEach(func() {
+			DeferCleanup(k8sClient.Delete, ctx, th.CreateConfigMap(
+				types.NamespacedName{Namespace: namespace, Name: "cinder-host-aliases"},
+				map[string]interface{}{
+					"keystone.example.com": "192.168.122.80",
+					"glance.example.com":   "192.168.122.80",
+				},
+			))
+			spec := GetDefaultCinderSpec()
+			spec["hostAliasesConfigMap"] = "cinder-host-aliases"
+			CreateCinderFixture(cinderTest, spec)
+		})
+		It("adds the host aliases to the pods", func() {
+			Eventually(func(g Gomega) {
+				ss := th.GetStatefulSet(cinderTest.CinderScheduler)
+				g.Expect(ss.Spec.Template.Spec.HostAliases).To(Equal([]corev1.HostAlias{
+					{
+						IP:        "192.168.122.80",
+						Hostnames: []string{"glance.example.com", "keystone.example.com"},
+					},
+				}))
+			}, timeout, interval).Should(Succeed())
+		})
+	})
+	When("Cinder CR instance is built with a CinderAPI ProbeConfig and SecurityContext", func() {
+		BeforeEach(func() {
+			apiSpec := GetDefaultCinderAPISpec()
+			apiSpec["probeConfig"] = map[string]interface{}{
+				"livenessTimeout":         10,
+				"readinessTimeout":        15,
+				"periodSeconds":           20,
+				"initialDelaySeconds":     30,
+				"startupFailureThreshold": 60,
+				"startupPeriodSeconds":    10,
+			}
+			apiSpec["securityContext"] = map[string]interface{}{
+				"runAsUser":  42407,
+				"runAsGroup": 42407,
+				"fsGroup":    42407,
+			}
+			spec := GetDefaultCinderSpec()
+			spec["cinderAPI"] = apiSpec
+			CreateCinderFixture(cinderTest, spec)
+		})
+		It("streams the log with the cinder-api image", func() {
+			Eventually(func(g Gomega) {
+				containers := th.GetStatefulSet(cinderTest.CinderAPI).Spec.Template.Spec.Containers
+				g.Expect(containers[0].Image).To(Equal(containers[1].Image))
+			}, timeout, interval).Should(Succeed())
+		})
+		It("runs the cinder-api containers as root", func() {
+			Eventually(func(g Gomega) {
+				podSpec := th.GetStatefulSet(cinderTest.CinderAPI).Spec.Template.Spec
+				for _, container := range podSpec.Containers {
+					g.Expect(*container.SecurityContext.RunAsUser).To(Equal(int64(0)))
+					g.Expect(container.SecurityContext.RunAsGroup).To(BeNil())
+				}
+				g.Expect(podSpec.SecurityContext.FSGroup).To(BeNil())
+			}, timeout, interval).Should(Succeed())
+		})
+		It("does not spread the cinder-api pods nor tolerate taints", func() {
+			Eventually(func(g Gomega) {
+				podSpec := th.GetStatefulSet(cinderTest.CinderAPI).Spec.Template.Spec
+				g.Expect(podSpec.Affinity).ToNot(BeNil())
+				g.Expect(podSpec.TopologySpreadConstraints).To(BeEmpty())
+				g.Expect(podSpec.Tolerations).To(BeEmpty())
+			}, timeout, interval).Should(Succeed())
+		})
+		It("runs kolla_start with bash", func() {
+			Eventually(func(g Gomega) {
+				container := th.GetStatefulSet(cinderTest.CinderAPI).Spec.Template.Spec.Containers[1]
+				g.Expect(container.Command).To(Equal([]string{"/bin/bash"}))
+				g.Expect(container.Args).To(Equal([]string{"-c", "/usr/local/bin/kolla_set_configs && /usr/local/bin/kolla_start"}))
+			}, timeout, interval).Should(Succeed())
+		})
+		It("delays the liveness probe with a startup probe", func() {
+			Eventually(func(g Gomega) {
+				container := th.GetStatefulSet(cinderTest.CinderAPI).Spec.Template.Spec.Containers[1]
+				g.Expect(container.StartupProbe).ToNot(BeNil())
+				g.Expect(container.StartupProbe.HTTPGet).To(Equal(container.LivenessProbe.HTTPGet))
+				g.Expect(container.StartupProbe.FailureThreshold).To(Equal(int32(12)))
+			}, timeout, interval).Should(Succeed())
+		})
+		It("probes the default healthcheck path", func() {
+			Eventually(func(g Gomega) {
+				container := th.GetStatefulSet(cinderTest.CinderAPI).Spec.Template.Spec.Containers[1]
+				g.Expect(container.LivenessProbe.HTTPGet.Path).To(Equal("/healthcheck"))
+				g.Expect(container.ReadinessProbe.HTTPGet.Path).To(Equal("/healthcheck"))
+			}, timeout, interval).Should(Succeed())
+		})
+		It("applies the probe timings to the cinder-api container", func() {
+			Eventually(func(g Gomega) {
+				ss := th.GetStatefulSet(cinderTest.CinderAPI)
+				container := ss.Spec.Template.Spec.Containers[1]
+				g.Expect(container.LivenessProbe.TimeoutSeconds).To(Equal(int32(10)))
+				g.Expect(container.ReadinessProbe.TimeoutSeconds).To(Equal(int32(15)))
+				for _, probe := range []*corev1.Probe{container.LivenessProbe, container.ReadinessProbe} {
+					g.Expect(probe.PeriodSeconds).To(Equal(int32(20)))
+					g.Expect(probe.InitialDelaySeconds).To(Equal(int32(30)))
+					g.Expect(probe.FailureThreshold).To(Equal(int32(3)))
+				}
+				g.Expect(container.StartupProbe.FailureThreshold).To(Equal(int32(60)))
+				g.Expect(container.StartupProbe.PeriodSeconds).To(Equal(int32(10)))
+			}, timeout, interval).Should(Succeed())
+		})
+		It("applies the security context to the cinder-api pods", func() {
+			Eventually(func(g Gomega) {
+				podSpec := th.GetStatefulSet(cinderTest.CinderAPI).Spec.Template.Spec
+				g.Expect(podSpec.SecurityContext).To(Equal(&corev1.PodSecurityContext{
+					RunAsUser:  ptr.To(int64(42407)),
+					RunAsGroup: ptr.To(int64(42407)),
+					FSGroup:    ptr.To(int64(42407)),
+				}))
+				for _, container := range podSpec.Containers {
+					g.Expect(container.SecurityContext).To(Equal(&corev1.SecurityContext{
+						RunAsUser:  ptr.To(int64(42407)),
+						RunAsGroup: ptr.To(int64(42407)),
+					}))
+				}
+			}, timeout, interval).Should(Succeed())
+		})
+	})
+	When("Cinder CR instance is built with CinderAPI in debug mode", func() {
+		BeforeEach(func() {
+			apiSpec := GetDefaultCinderAPISpec()
+			apiSpec["debug"] = map[string]interface{}{"service": true}
+			spec := GetDefaultCinderSpec()
+			spec["cinderAPI"] = apiSpec
+			CreateCinderFixture(cinderTest, spec)
+		})
+		It("does not set a startup probe", func() {
+			Eventually(func(g Gomega) {
+				container := th.GetStatefulSet(cinderTest.CinderAPI).Spec.Template.Spec.Containers[1]
+				g.Expect(container.LivenessProbe.Exec).ToNot(BeNil())
+				g.Expect(container.StartupProbe).To(BeNil())
+			}, timeout, interval).Should(Succeed())
+		})
+		It("runs the debug command", func() {
+			Eventually(func(g Gomega) {
+				container := th.GetStatefulSet(cinderTest.CinderAPI).Spec.Template.Spec.Containers[1]
+				g.Expect(container.Command).To(Equal([]string{"/bin/bash"}))
+				g.Expect(container.Args).To(Equal([]string{"-c", common.DebugCommand}))
+			}, timeout, interval).Should(Succeed())
+		})
+	})
+	When("Cinder CR instance is built with a CinderAPI container command and log image", func() {
+		BeforeEach(func() {
+			apiSpec := GetDefaultCinderAPISpec()
+			apiSpec["containerCommand"] = []string{"/usr/local/bin/entrypoint.sh"}
+			apiSpec["containerArgs"] = []string{"--verbose"}
+			apiSpec["logImage"] = "quay.io/podified-antelope-centos9/openstack-base:current-podified"
+			spec := GetDefaultCinderSpec()
+			spec["cinderAPI"] = apiSpec
+			CreateCinderFixture(cinderTest, spec)
+		})
+		It("runs the custom command", func() {
+			Eventually(func(g Gomega) {
+				container := th.GetStatefulSet(cinderTest.CinderAPI).Spec.Template.Spec.Containers[1]
+				g.Expect(container.Command).To(Equal([]string{"/usr/local/bin/entrypoint.sh"}))
+				g.Expect(container.Args).To(Equal([]string{"--verbose"}))
+			}, timeout, interval).Should(Succeed())
+		})
+		It("streams the log with the log image", func() {
+			Eventually(func(g Gomega) {
+				containers := th.GetStatefulSet(cinderTest.CinderAPI).Spec.Template.Spec.Containers
+				g.Expect(containers[0].Image).To(Equal("quay.io/podified-antelope-centos9/openstack-base:current-podified"))
+				g.Expect(containers[1].Image).To(Equal(cinderTest.ContainerImage))
+			}, timeout, interval).Should(Succeed())
+		})
+		It("has no preStop hook without a termination grace period", func() {
+			Eventually(func(g Gomega) {
+				podSpec := th.GetStatefulSet(cinderTest.CinderAPI).Spec.Template.Spec
+				g.Expect(podSpec.Containers[1].Lifecycle).To(BeNil())
+			}, timeout, interval).Should(Succeed())
+		})
+		It("gives the log container the default log resources", func() {
+			Eventually(func(g Gomega) {
+				logContainer := th.GetStatefulSet(cinderTest.CinderAPI).Spec.Template.Spec.Containers[0]
+				g.Expect(logContainer.Resources.Requests.Cpu().Equal(resource.MustParse(cinderapi.DefaultLogCPURequest))).To(BeTrue())
+				g.Expect(logContainer.Resources.Requests.Memory().Equal(resource.MustParse(cinderapi.DefaultLogMemoryRequest))).To(BeTrue())
+				g.Expect(logContainer.Resources.Limits.Memory().Equal(resource.MustParse(cinderapi.DefaultLogMemoryLimit))).To(BeTrue())
+			}, timeout, interval).Should(Succeed())
+		})
+	})
+	When("Cinder CR instance is built with a CinderAPI termination grace period", func() {
+		BeforeEach(func() {
+			apiSpec := GetDefaultCinderAPISpec()
+			apiSpec["terminationGracePeriodSeconds"] = 60
+			spec := GetDefaultCinderSpec()
+			spec["cinderAPI"] = apiSpec
+			CreateCinderFixture(cinderTest, spec)
+			keystone.SimulateKeystoneServiceReady(cinderTest.CinderKeystoneService)
+		})
+		It("sets the grace period and the preStop hook of the API container", func() {
+			Eventually(func(g Gomega) {
+				podSpec := th.GetStatefulSet(cinderTest.CinderAPI).Spec.Template.Spec
+				g.Expect(podSpec.TerminationGracePeriodSeconds).To(Equal(ptr.To(int64(60))))
+				lifecycle := podSpec.Containers[1].Lifecycle
+				g.Expect(lifecycle).ToNot(BeNil())
+				g.Expect(lifecycle.PreStop.Exec.Command).To(Equal([]string{"/bin/sleep", "5"}))
+				g.Expect(podSpec.Containers[0].Lifecycle).To(BeNil())
+			}, timeout, interval).Should(Succeed())
+		})
+	})
+	When("Cinder CR instance is built with extra CinderAPI CA bundles", func() {
+		BeforeEach(func() {
+			apiSpec := GetDefaultCinderAPISpec()
+			apiSpec["extraCaBundleSecretNames"] = []string{"storage-ca", "ceph-dashboard-ca"}
+			spec := GetDefaultCinderSpec()
+			spec["cinderAPI"] = apiSpec
+			for _, secretName := range []string{"storage-ca", "ceph-dashboard-ca"} {
+				DeferCleanup(k8sClient.Delete, ctx, th.CreateSecret(
+					types.NamespacedName{Name: secretName, Namespace: namespace},
+					map[string][]byte{"ca.crt": []byte("CAData")},
+				))
+			}
+			CreateCinderFixture(cinderTest, spec)
+			keystone.SimulateKeystoneServiceReady(cinderTest.CinderKeystoneService)
+		})
+		It("mounts every CA bundle in the API container", func() {
+			Eventually(func(g Gomega) {
+				podSpec := th.GetStatefulSet(cinderTest.CinderAPI).Spec.Template.Spec
+				g.Expect(podSpec.Volumes).To(ContainElement(And(
+					HaveField("Name", "extra-ca-bundle-0"),
+					HaveField("Secret.SecretName", "storage-ca"))))
+				g.Expect(podSpec.Volumes).To(ContainElement(And(
+					HaveField("Name", "extra-ca-bundle-1"),
+					HaveField("Secret.SecretName", "ceph-dashboard-ca"))))
+				g.Expect(podSpec.Containers[1].VolumeMounts).To(ContainElement(And(
+					HaveField("Name", "extra-ca-bundle-0"),
+					HaveField("MountPath", "/etc/pki/cinder/ca-bundles/storage-ca"))))
+				g.Expect(podSpec.Containers[1].VolumeMounts).To(ContainElement(And(
+					HaveField("Name", "extra-ca-bundle-1"),
+					HaveField("MountPath", "/etc/pki/cinder/ca-bundles/ceph-dashboard-ca"))))
+			}, timeout, interval).Should(Succeed())
+		})
+		It("restarts the CinderAPI pods when an extra CA bundle changes", func() {
+			var configHash string
+			Eventually(func(g Gomega) {
+				configHash = GetEnvVarValue(
+					th.GetStatefulSet(cinderTest.CinderAPI).Spec.Template.Spec.Containers[1].Env, "CONFIG_HASH", "")
+				g.Expect(configHash).ToNot(BeEmpty())
+			}, timeout, interval).Should(Succeed())
+
+			th.UpdateSecret(types.NamespacedName{Name: "storage-ca", Namespace: namespace}, "ca.crt", []byte("DifferentCAData"))
+
+			Eventually(func(g Gomega) {
+				g.Expect(GetEnvVarValue(
+					th.GetStatefulSet(cinderTest.CinderAPI).Spec.Template.Spec.Containers[1].Env, "CONFIG_HASH", "")).ToNot(Equal(configHash))
+			}, timeout, interval).Should(Succeed())
+		})
+	})
+	When("Cinder CR instance is built with CinderAPI log resources", func() {
+		BeforeEach(func() {
+			apiSpec := GetDefaultCinderAPISpec()
+			apiSpec["resources"] = map[string]interface{}{
+				"requests": map[string]interface{}{"memory": "1Gi"},
+			}
+			apiSpec["logResources"] = map[string]interface{}{
+				"requests": map[string]interface{}{"cpu": "10m", "memory": "16Mi"},
+			}
+			spec := GetDefaultCinderSpec()
+			spec["cinderAPI"] = apiSpec
+			CreateCinderFixture(cinderTest, spec)
+			keystone.SimulateKeystoneServiceReady(cinderTest.CinderKeystoneService)
+		})
+		It("sets the log resources on the log container only", func() {
+			Eventually(func(g Gomega) {
+				containers := th.GetStatefulSet(cinderTest.CinderAPI).Spec.Template.Spec.Containers
+				g.Expect(containers[0].Resources.Requests.Cpu().Equal(resource.MustParse("10m"))).To(BeTrue())
+				g.Expect(containers[0].Resources.Requests.Memory().Equal(resource.MustParse("16Mi"))).To(BeTrue())
+				g.Expect(containers[0].Resources.Limits).To(BeEmpty())
+				g.Expect(containers[1].Resources.Requests.Memory().Equal(resource.MustParse("1Gi"))).To(BeTrue())
+			}, timeout, interval).Should(Succeed())
+		})
+	})
+	When("Cinder CR instance is built with several CinderAPI replicas", func() {
+		BeforeEach(func() {
+			apiSpec := GetDefaultCinderAPISpec()
+			apiSpec["replicas"] = 3
+			apiSpec["tolerations"] = []map[string]interface{}{
+				{
+					"key":      "storage",
+					"operator": "Exists",
+					"effect":   "NoSchedule",
+				},
+			}
+			apiSpec["topologySpreadConstraints"] = []map[string]interface{}{
+				{
+					"maxSkew":           1,
+					"topologyKey":       "topology.kubernetes.io/zone",
+					"whenUnsatisfiable": "ScheduleAnyway",
+				},
+			}
+			spec := GetDefaultCinderSpec()
+			spec["cinderAPI"] = apiSpec
+			CreateCinderFixture(cinderTest, spec)
+		})
+		It("reports the rollout progress until all the replicas are ready", func() {
+			Eventually(func(g Gomega) {
+				ss := th.GetStatefulSet(cinderTest.CinderAPI)
+				ss.Status.Replicas = 3
+				ss.Status.ReadyReplicas = 1
+				ss.Status.UpdatedReplicas = 1
+				g.Expect(k8sClient.Status().Update(ctx, ss)).To(Succeed())
+			}, timeout, interval).Should(Succeed())
+
+			th.ExpectConditionWithDetails(
+				cinderTest.CinderAPI,
+				ConditionGetterFunc(CinderAPIConditionGetter),
+				condition.DeploymentReadyCondition,
+				corev1.ConditionFalse,
+				condition.RequestedReason,
+				fmt.Sprintf(cinderv1.DeploymentRolloutProgressMessage, 1, 3, 1),
+			)
+
+			Eventually(func(g Gomega) {
+				ss := th.GetStatefulSet(cinderTest.CinderAPI)
+				ss.Status.ReadyReplicas = 3
+				ss.Status.UpdatedReplicas = 3
+				g.Expect(k8sClient.Status().Update(ctx, ss)).To(Succeed())
+			}, timeout, interval).Should(Succeed())
+
+			th.ExpectCondition(
+				cinderTest.CinderAPI,
+				ConditionGetterFunc(CinderAPIConditionGetter),
+				condition.DeploymentReadyCondition,
+				corev1.ConditionTrue,
+			)
+		})
+		It("creates a PodDisruptionBudget for the cinder-api pods", func() {
+			Eventually(func(g Gomega) {
+				pdb := &policyv1.PodDisruptionBudget{}
+				g.Expect(k8sClient.Get(ctx, cinderTest.CinderAPI, pdb)).To(Succeed())
+				g.Expect(pdb.Spec.MaxUnavailable).To(Equal(ptr.To(intstr.FromInt(1))))
+				g.Expect(pdb.Spec.Selector.MatchLabels).To(Equal(th.GetStatefulSet(cinderTest.CinderAPI).Spec.Selector.MatchLabels))
+			}, timeout, interval).Should(Succeed())
+		})
+		It("spreads the cinder-api pods across zones", func() {
+			Eventually(func(g Gomega) {
+				ss := th.GetStatefulSet(cinderTest.CinderAPI)
+				constraints := ss.Spec.Template.Spec.TopologySpreadConstraints
+				g.Expect(constraints).To(HaveLen(1))
+				g.Expect(constraints[0].TopologyKey).To(Equal("topology.kubernetes.io/zone"))
+				g.Expect(constraints[0].LabelSelector.MatchLabels).To(Equal(ss.Spec.Selector.MatchLabels))
+			}, timeout, interval).Should(Succeed())
+		})
+		It("tolerates the storage nodes taint", func() {
+			Eventually(func(g Gomega) {
+				ss := th.GetStatefulSet(cinderTest.CinderAPI)
+				g.Expect(ss.Spec.Template.Spec.Tolerations).To(Equal([]corev1.Toleration{
+					{
+						Key:      "storage",
+						Operator: corev1.TolerationOpExists,
+						Effect:   corev1.TaintEffectNoSchedule,
+					},
+				}))
+			}, timeout, interval).Should(Succeed())
+		})
+		It("updates the PodDisruptionBudget", func() {
+			Eventually(func(g Gomega) {
+				cinder := GetCinder(cinderTest.Instance)
+				cinder.Spec.CinderAPI.MaxUnavailable = ptr.To(intstr.FromString("50%"))
+				g.Expect(k8sClient.Update(ctx, cinder)).To(Succeed())
+			}, timeout, interval).Should(Succeed())
+			Eventually(func(g Gomega) {
+				pdb := &policyv1.PodDisruptionBudget{}
+				g.Expect(k8sClient.Get(ctx, cinderTest.CinderAPI, pdb)).To(Succeed())
+				g.Expect(pdb.Spec.MaxUnavailable).To(Equal(ptr.To(intstr.FromString("50%"))))
+			}, timeout, interval).Should(Succeed())
+		})
+		It("deletes the PodDisruptionBudget when scaled down to a single replica", func() {
+			Eventually(func(g Gomega) {
+				g.Expect(k8sClient.Get(ctx, cinderTest.CinderAPI, &policyv1.PodDisruptionBudget{})).To(Succeed())
+			}, timeout, interval).Should(Succeed())
+			Eventually(func(g Gomega) {
+				cinder := GetCinder(cinderTest.Instance)
+				cinder.Spec.CinderAPI.Replicas = ptr.To(int32(1))
+				g.Expect(k8sClient.Update(ctx, cinder)).To(Succeed())
+			}, timeout, interval).Should(Succeed())
+			Eventually(func(g Gomega) {
+				g.Expect(k8sClient.Get(ctx, cinderTest.CinderAPI, &policyv1.PodDisruptionBudget{})).ToNot(Succeed())
+			}, timeout, interval).Should(Succeed())
+		})
+	})
+	When("Cinder CR instance is built with CinderAPI autoscaling", func() {
+		BeforeEach(func() {
+			apiSpec := GetDefaultCinderAPISpec()
+			apiSpec["autoscaling"] = map[string]interface{}{
+				"minReplicas": 2,
+				"maxReplicas": 5,
+			}
+			spec := GetDefaultCinderSpec()
+			spec["cinderAPI"] = apiSpec
+			CreateCinderFixture(cinderTest, spec)
+		})
+		It("creates a HorizontalPodAutoscaler for the cinder-api StatefulSet", func() {
+			Eventually(func(g Gomega) {
+				hpa := &autoscalingv2.HorizontalPodAutoscaler{}
+				g.Expect(k8sClient.Get(ctx, cinderTest.CinderAPI, hpa)).To(Succeed())
+				g.Expect(hpa.Spec.ScaleTargetRef.Kind).To(Equal("StatefulSet"))
+				g.Expect(hpa.Spec.ScaleTargetRef.Name).To(Equal(cinderTest.CinderAPI.Name))
+				g.Expect(*hpa.Spec.MinReplicas).To(Equal(int32(2)))
+				g.Expect(hpa.Spec.MaxReplicas).To(Equal(int32(5)))
+				g.Expect(*hpa.Spec.Metrics[0].Resource.Target.AverageUtilization).To(Equal(int32(80)))
+
+				g.Expect(*th.GetStatefulSet(cinderTest.CinderAPI).Spec.Replicas).To(Equal(int32(2)))
+			}, timeout, interval).Should(Succeed())
+		})
+		It("updates the HorizontalPodAutoscaler", func() {
+			Eventually(func(g Gomega) {
+				cinder := GetCinder(cinderTest.Instance)
+				cinder.Spec.CinderAPI.Autoscaling.MaxReplicas = 10
+				g.Expect(k8sClient.Update(ctx, cinder)).To(Succeed())
+			}, timeout, interval).Should(Succeed())
+			Eventually(func(g Gomega) {
+				hpa := &autoscalingv2.HorizontalPodAutoscaler{}
+				g.Expect(k8sClient.Get(ctx, cinderTest.CinderAPI, hpa)).To(Succeed())
+				g.Expect(hpa.Spec.MaxReplicas).To(Equal(int32(10)))
+			}, timeout, interval).Should(Succeed())
+		})
+		It("deletes the HorizontalPodAutoscaler when autoscaling is disabled", func() {
+			Eventually(func(g Gomega) {
+				g.Expect(k8sClient.Get(ctx, cinderTest.CinderAPI, &autoscalingv2.HorizontalPodAutoscaler{})).To(Succeed())
+			}, timeout, interval).Should(Succeed())
+			Eventually(func(g Gomega) {
+				cinder := GetCinder(cinderTest.Instance)
+				cinder.Spec.CinderAPI.Autoscaling = nil
+				g.Expect(k8sClient.Update(ctx, cinder)).To(Succeed())
+			}, timeout, interval).Should(Succeed())
+			Eventually(func(g Gomega) {
+				g.Expect(k8sClient.Get(ctx, cinderTest.CinderAPI, &autoscalingv2.HorizontalPodAutoscaler{})).ToNot(Succeed())
+			}, timeout, interval).Should(Succeed())
+		})
+	})
+	When("Cinder CR instance is built with CinderAPI autoscaling below the spec replicas", func() {
+		BeforeEach(func() {
+			apiSpec := GetDefaultCinderAPISpec()
+			apiSpec["replicas"] = 3
+			apiSpec["progressDeadlineSeconds"] = 1
+			apiSpec["autoscaling"] = map[string]interface{}{
+				"minReplicas": 1,
+				"maxReplicas": 5,
+			}
+			spec := GetDefaultCinderSpec()
+			spec["cinderAPI"] = apiSpec
+			CreateCinderFixture(cinderTest, spec)
+			keystone.SimulateKeystoneServiceReady(cinderTest.CinderKeystoneService)
+			keystone.SimulateKeystoneEndpointReady(cinderTest.CinderKeystoneEndpoint)
+		})
+		It("completes the rollout with the replicas held by the HorizontalPodAutoscaler", func() {
+			Eventually(func(g Gomega) {
+				ss := th.GetStatefulSet(cinderTest.CinderAPI)
+				g.Expect(*ss.Spec.Replicas).To(Equal(int32(1)))
+				ss.Status.ObservedGeneration = ss.Generation
+				ss.Status.Replicas = 1
+				ss.Status.ReadyReplicas = 1
+				ss.Status.UpdatedReplicas = 1
+				ss.Status.CurrentRevision = "rev-1"
+				ss.Status.UpdateRevision = "rev-1"
+				g.Expect(k8sClient.Status().Update(ctx, ss)).To(Succeed())
+			}, timeout, interval).Should(Succeed())
+
+			Eventually(func(g Gomega) {
+				g.Expect(GetCinderAPI(cinderTest.CinderAPI).Status.ReadyCount).To(Equal(int32(1)))
+			}, timeout, interval).Should(Succeed())
+			Consistently(func(g Gomega) {
+				cinderAPI := GetCinderAPI(cinderTest.CinderAPI)
+				g.Expect(cinderAPI.Status.Conditions.Has(cinderv1.RolloutStuckCondition)).To(BeFalse())
+				g.Expect(cinderAPI.Status.RolloutStartTime).To(BeNil())
+			}, time.Second*3, interval).Should(Succeed())
+		})
+	})
+	When("Cinder CR instance is built with the CinderAPI ServiceMonitor enabled", func() {
+		BeforeEach(func() {
+			apiSpec := GetDefaultCinderAPISpec()
+			apiSpec["enableServiceMonitor"] = true
+			spec := GetDefaultCinderSpec()
+			spec["cinderAPI"] = apiSpec
+			CreateCinderFixture(cinderTest, spec)
+		})
+		It("builds a ServiceMonitor scraping the internal API service", func() {
+			instance := GetCinderAPI(cinderTest.CinderAPI)
+			selector := map[string]string{"service": "cinder", "endpoint": "internal"}
+			serviceMonitor := cinderapi.ServiceMonitor(instance, map[string]string{"service": "cinder"}, selector, "cinder-internal", "https")
+
+			Expect(serviceMonitor.GroupVersionKind()).To(Equal(cinderapi.ServiceMonitorGVK))
+			Expect(serviceMonitor.GetName()).To(Equal(cinderTest.CinderAPI.Name))
+			Expect(serviceMonitor.GetNamespace()).To(Equal(cinderTest.CinderAPI.Namespace))
+			matchLabels, _, _ := unstructured.NestedStringMap(serviceMonitor.Object, "spec", "selector", "matchLabels")
+			Expect(matchLabels).To(Equal(selector))
+			endpoints, _, _ := unstructured.NestedSlice(serviceMonitor.Object, "spec", "endpoints")
+			Expect(endpoints).To(HaveLen(1))
+			Expect(endpoints[0]).To(HaveKeyWithValue("port", "cinder-internal"))
+			Expect(endpoints[0]).To(HaveKeyWithValue("path", "/metrics"))
+			Expect(endpoints[0]).To(HaveKeyWithValue("scheme", "https"))
+		})
+		It("skips the ServiceMonitor when its CRD is not installed", func() {
+			th.ExpectConditionWithDetails(
+				cinderTest.CinderAPI,
+				ConditionGetterFunc(CinderAPIConditionGetter),
+				cinderv1.CinderAPIServiceMonitorReadyCondition,
+				corev1.ConditionFalse,
+				condition.ErrorReason,
+				cinderv1.CinderAPIServiceMonitorReadyCRDMissingMessage,
+			)
+			th.GetStatefulSet(cinderTest.CinderAPI)
+		})
+		It("removes the condition when the ServiceMonitor is disabled", func() {
+			th.ExpectCondition(
+				cinderTest.CinderAPI,
+				ConditionGetterFunc(CinderAPIConditionGetter),
+				cinderv1.CinderAPIServiceMonitorReadyCondition,
+				corev1.ConditionFalse,
+			)
+			Eventually(func(g Gomega) {
+				cinder := GetCinder(cinderTest.Instance)
+				cinder.Spec.CinderAPI.EnableServiceMonitor = false
+				g.Expect(k8sClient.Update(ctx, cinder)).To(Succeed())
+			}, timeout, interval).Should(Succeed())
+			Eventually(func(g Gomega) {
+				conditions := CinderAPIConditionGetter(cinderTest.CinderAPI)
+				g.Expect(conditions.Has(cinderv1.CinderAPIServiceMonitorReadyCondition)).To(BeFalse())
+			}, timeout, interval).Should(Succeed())
+		})
+	})
+	When("Cinder CR instance is built with CinderAPI port overrides", func() {
+		BeforeEach(func() {
+			apiSpec := GetDefaultCinderAPISpec()
+			apiSpec["apiPorts"] = map[string]interface{}{
+				"public":   8080,
+				"internal": 8081,
+			}
+			spec := GetDefaultCinderSpec()
+			spec["cinderAPI"] = apiSpec
+			CreateCinderFixture(cinderTest, spec)
+			keystone.SimulateKeystoneServiceReady(cinderTest.CinderKeystoneService)
+		})
+		It("exposes and registers the endpoints on the configured ports", func() {
+			Eventually(func(g Gomega) {
+				g.Expect(th.GetService(cinderTest.CinderServicePublic).Spec.Ports[0].Port).To(Equal(int32(8080)))
+				g.Expect(th.GetService(cinderTest.CinderServiceInternal).Spec.Ports[0].Port).To(Equal(int32(8081)))
+
+				endpoints := keystone.GetKeystoneEndpoint(cinderTest.CinderKeystoneEndpoint).Spec.Endpoints
+				g.Expect(endpoints).To(HaveKeyWithValue("public", "http://cinder-public."+namespace+".svc:8080/v3"))
+				g.Expect(endpoints).To(HaveKeyWithValue("internal", "http://cinder-internal."+namespace+".svc:8081/v3"))
+			}, timeout, interval).Should(Succeed())
+		})
+		It("probes the cinder-api on the configured port", func() {
+			Eventually(func(g Gomega) {
+				container := th.GetStatefulSet(cinderTest.CinderAPI).Spec.Template.Spec.Containers[1]
+				g.Expect(container.LivenessProbe.HTTPGet.Port.IntVal).To(Equal(int32(8080)))
+				g.Expect(container.ReadinessProbe.HTTPGet.Port.IntVal).To(Equal(int32(8080)))
+			}, timeout, interval).Should(Succeed())
+		})
+		It("listens on the configured ports", func() {
+			Eventually(func(g Gomega) {
+				configData := th.GetSecret(types.NamespacedName{
+					Namespace: cinderTest.Instance.Namespace,
+					Name:      fmt.Sprintf("%s-config-data", cinderTest.Instance.Name),
+				})
+				httpdConf := string(configData.Data["httpd.conf"])
+				g.Expect(httpdConf).To(ContainSubstring("Listen 8080"))
+				g.Expect(httpdConf).To(ContainSubstring("Listen 8081"))
+				g.Expect(httpdConf).ToNot(ContainSubstring("Listen 8776"))
+				vhosts := string(configData.Data["10-cinder_wsgi.conf"])
+				g.Expect(vhosts).To(ContainSubstring("<VirtualHost *:8080>"))
+				g.Expect(vhosts).To(ContainSubstring("<VirtualHost *:8081>"))
+			}, timeout, interval).Should(Succeed())
+		})
+	})
+	When("Cinder CR instance is built with CinderAPI pod labels and annotations", func() {
+		BeforeEach(func() {
+			apiSpec := GetDefaultCinderAPISpec()
+			apiSpec["podLabels"] = map[string]interface{}{
+				"network-policy":         "storage",
+				common.ComponentSelector: "overridden",
+			}
+			apiSpec["podAnnotations"] = map[string]interface{}{
+				"sidecar.istio.io/inject": "true",
+			}
+			spec := GetDefaultCinderSpec()
+			spec["cinderAPI"] = apiSpec
+			CreateCinderFixture(cinderTest, spec)
+			keystone.SimulateKeystoneServiceReady(cinderTest.CinderKeystoneService)
+		})
+		It("adds them to the API pods", func() {
+			Eventually(func(g Gomega) {
+				ss := th.GetStatefulSet(cinderTest.CinderAPI)
+				g.Expect(ss.Spec.Template.Labels).To(HaveKeyWithValue("network-policy", "storage"))
+				g.Expect(ss.Spec.Template.Annotations).To(HaveKeyWithValue("sidecar.istio.io/inject", "true"))
+			}, timeout, interval).Should(Succeed())
+		})
+		It("preserves the operator managed labels", func() {
+			Eventually(func(g Gomega) {
+				ss := th.GetStatefulSet(cinderTest.CinderAPI)
+				g.Expect(ss.Spec.Template.Labels).To(HaveKeyWithValue(common.AppSelector, "cinder"))
+				g.Expect(ss.Spec.Template.Labels).To(HaveKeyWithValue(common.ComponentSelector, "cinder-api"))
+				g.Expect(ss.Spec.Selector.MatchLabels).NotTo(HaveKey("network-policy"))
+			}, timeout, interval).Should(Succeed())
+		})
+	})
+	When("Cinder CR instance is built with the default CinderAPI", func() {
+		BeforeEach(func() {
+			apiSpec := GetDefaultCinderAPISpec()
+			spec := GetDefaultCinderSpec()
+			spec["cinderAPI"] = apiSpec
+			CreateCinderFixture(cinderTest, spec)
+			keystone.SimulateKeystoneServiceReady(cinderTest.CinderKeystoneService)
+		})
+		It("waits for the database in an init container", func() {
+			Eventually(func(g Gomega) {
+				ss := th.GetStatefulSet(cinderTest.CinderAPI)
+				g.Expect(ss.Spec.Template.Spec.InitContainers).To(HaveLen(1))
+				initContainer := ss.Spec.Template.Spec.InitContainers[0]
+				g.Expect(initContainer.Name).To(Equal(cinderapi.DBWaitContainerName))
+				g.Expect(initContainer.Image).To(Equal(cinderTest.ContainerImage))
+				g.Expect(initContainer.Args).To(Equal([]string{"-c", cinderapi.DBWaitCommand}))
+				g.Expect(initContainer.VolumeMounts).To(ContainElement(corev1.VolumeMount{
+					Name:      "config-data",
+					MountPath: "/var/lib/config-data/merged",
+					ReadOnly:  true,
+				}))
+			}, timeout, interval).Should(Succeed())
+		})
+		It("renders the database connection read by the init container", func() {
+			Eventually(func(g Gomega) {
+				configData := th.GetSecret(types.NamespacedName{
+					Namespace: cinderTest.Instance.Namespace,
+					Name:      fmt.Sprintf("%s-config-data", cinderTest.Instance.Name),
+				})
+				conf := string(configData.Data["00-global-defaults.conf"])
+				g.Expect(conf).To(MatchRegexp(`(?m)^connection = mysql\+pymysql://[^@]*@hostname-for-openstack\.%s\.svc/`, namespace))
+			}, timeout, interval).Should(Succeed())
+		})
+	})
+	When("Cinder CR instance is built with waitForDatabase disabled", func() {
+		BeforeEach(func() {
+			apiSpec := GetDefaultCinderAPISpec()
+			apiSpec["waitForDatabase"] = false
+			spec := GetDefaultCinderSpec()
+			spec["cinderAPI"] = apiSpec
+			CreateCinderFixture(cinderTest, spec)
+			keystone.SimulateKeystoneServiceReady(cinderTest.CinderKeystoneService)
+		})
+		It("doesn't add the init container", func() {
+			Eventually(func(g Gomega) {
+				ss := th.GetStatefulSet(cinderTest.CinderAPI)
+				g.Expect(ss.Spec.Template.Spec.InitContainers).To(BeEmpty())
+			}, timeout, interval).Should(Succeed())
+		})
+	})
+	When("Cinder CR instance is built with CinderAPI image pull secrets", func() {
+		BeforeEach(func() {
+			apiSpec := GetDefaultCinderAPISpec()
+			apiSpec["imagePullSecrets"] = []interface{}{
+				map[string]interface{}{"name": "registry-pull-secret"},
+			}
+			spec := GetDefaultCinderSpec()
+			spec["cinderAPI"] = apiSpec
+			CreateCinderFixture(cinderTest, spec)
+			keystone.SimulateKeystoneServiceReady(cinderTest.CinderKeystoneService)
+		})
+		It("sets them on the API pods", func() {
+			Eventually(func(g Gomega) {
+				ss := th.GetStatefulSet(cinderTest.CinderAPI)
+				g.Expect(ss.Spec.Template.Spec.ImagePullSecrets).To(Equal(
+					[]corev1.LocalObjectReference{{Name: "registry-pull-secret"}}))
+			}, timeout, interval).Should(Succeed())
+		})
+	})
+	When("Cinder CR instance is built with a CinderAPI priority class", func() {
+		BeforeEach(func() {
+			apiSpec := GetDefaultCinderAPISpec()
+			apiSpec["priorityClassName"] = "openstack-critical"
+			spec := GetDefaultCinderSpec()
+			spec["cinderAPI"] = apiSpec
+			CreateCinderFixture(cinderTest, spec)
+			keystone.SimulateKeystoneServiceReady(cinderTest.CinderKeystoneService)
+		})
+		It("sets it on the API pods", func() {
+			Eventually(func(g Gomega) {
+				ss := th.GetStatefulSet(cinderTest.CinderAPI)
+				g.Expect(ss.Spec.Template.Spec.PriorityClassName).To(Equal("openstack-critical"))
+			}, timeout, interval).Should(Succeed())
+		})
+	})
+	When("Cinder CR instance is built with a CinderAPI update strategy", func() {
+		BeforeEach(func() {
+			apiSpec := GetDefaultCinderAPISpec()
+			apiSpec["replicas"] = 3
+			apiSpec["updateStrategy"] = map[string]interface{}{
+				"type": "RollingUpdate",
+				"rollingUpdate": map[string]interface{}{
+					"partition":      2,
+					"maxUnavailable": "50%",
+				},
+			}
+			spec := GetDefaultCinderSpec()
+			spec["cinderAPI"] = apiSpec
+			CreateCinderFixture(cinderTest, spec)
+			keystone.SimulateKeystoneServiceReady(cinderTest.CinderKeystoneService)
+		})
+		It("applies the RollingUpdate settings to the StatefulSet", func() {
+			Eventually(func(g Gomega) {
+				ss := th.GetStatefulSet(cinderTest.CinderAPI)
+				g.Expect(ss.Spec.UpdateStrategy.Type).To(Equal(appsv1.RollingUpdateStatefulSetStrategyType))
+				g.Expect(ss.Spec.UpdateStrategy.RollingUpdate).NotTo(BeNil())
+				g.Expect(*ss.Spec.UpdateStrategy.RollingUpdate.Partition).To(Equal(int32(2)))
+				g.Expect(*ss.Spec.UpdateStrategy.RollingUpdate.MaxUnavailable).To(Equal(intstr.FromString("50%")))
+			}, timeout, interval).Should(Succeed())
+		})
+		It("applies an updated strategy", func() {
+			Eventually(func(g Gomega) {
+				cinder := GetCinder(cinderTest.Instance)
+				cinder.Spec.CinderAPI.UpdateStrategy = &appsv1.StatefulSetUpdateStrategy{
+					Type: appsv1.OnDeleteStatefulSetStrategyType,
+				}
+				g.Expect(k8sClient.Update(ctx, cinder)).To(Succeed())
+			}, timeout, interval).Should(Succeed())
+
+			Eventually(func(g Gomega) {
+				ss := th.GetStatefulSet(cinderTest.CinderAPI)
+				g.Expect(ss.Spec.UpdateStrategy.Type).To(Equal(appsv1.OnDeleteStatefulSetStrategyType))
+				g.Expect(ss.Spec.UpdateStrategy.RollingUpdate).To(BeNil())
+			}, timeout, interval).Should(Succeed())
+		})
+	})
+	When("CinderAPI is reconciled successfully", func() {
+		BeforeEach(func() {
+			spec := GetDefaultCinderSpec()
+			CreateCinderFixture(cinderTest, spec)
+			keystone.SimulateKeystoneServiceReady(cinderTest.CinderKeystoneService)
+		})
+		It("records events for the reconcile transitions", func() {
+			keystone.SimulateKeystoneEndpointReady(cinderTest.CinderKeystoneEndpoint)
+			th.SimulateStatefulSetReplicaReady(cinderTest.CinderAPI)
+
+			Eventually(func(g Gomega) {
+				g.Expect(GetEventReasons(g, cinderTest.CinderAPI)).To(ContainElements(
+					"ServicesExposed",
+					"KeystoneServiceReady",
+					"KeystoneEndpointsExposed",
+					"StatefulSetCreated",
+				))
+			}, timeout, interval).Should(Succeed())
+		})
+		It("exposes the public and internal endpoints of the V3 API", func() {
+			Eventually(func(g Gomega) {
+				apiEndpoints := GetCinderAPI(cinderTest.CinderAPI).Status.APIEndpoints
+				g.Expect(apiEndpoints).To(HaveLen(1))
+				g.Expect(apiEndpoints["cinderv3"]).To(Equal(map[string]string{
+					"public":   fmt.Sprintf("http://cinder-public.%s.svc:8776/v3", namespace),
+					"internal": fmt.Sprintf("http://cinder-internal.%s.svc:8776/v3", namespace),
+				}))
+			}, timeout, interval).Should(Succeed())
+		})
+		It("records an event when the StatefulSet is updated", func() {
+			th.SimulateStatefulSetReplicaReady(cinderTest.CinderAPI)
+
+			Eventually(func(g Gomega) {
+				cinder := GetCinder(cinderTest.Instance)
+				cinder.Spec.CinderAPI.ExtraEnv = map[string]string{"HTTPS_PROXY": "http://proxy.example.com:3128"}
+				g.Expect(k8sClient.Update(ctx, cinder)).To(Succeed())
+			}, timeout, interval).Should(Succeed())
+
+			Eventually(func(g Gomega) {
+				g.Expect(GetEventReasons(g, cinderTest.CinderAPI)).To(ContainElement("StatefulSetUpdated"))
+			}, timeout, interval).Should(Succeed())
+		})
+	})
+	When("Cinder CR instance is built with a CinderAPI service name", func() {
+		BeforeEach(func() {
+			apiSpec := GetDefaultCinderAPISpec()
+			apiSpec["serviceName"] = "block-storage"
+			spec := GetDefaultCinderSpec()
+			spec["cinderAPI"] = apiSpec
+			CreateCinderFixture(cinderTest, spec)
+			keystone.SimulateKeystoneServiceReady(cinderTest.CinderKeystoneService)
+		})
+		It("names the Services of the endpoint types after it", func() {
+			th.AssertServiceExists(types.NamespacedName{Namespace: namespace, Name: "block-storage-public"})
+			th.AssertServiceExists(types.NamespacedName{Namespace: namespace, Name: "block-storage-internal"})
+			th.AssertServiceDoesNotExist(types.NamespacedName{Namespace: namespace, Name: "cinder-public"})
+			th.AssertServiceDoesNotExist(types.NamespacedName{Namespace: namespace, Name: "cinder-internal"})
+		})
+		It("deletes the Services of the previous service name", func() {
+			th.AssertServiceExists(types.NamespacedName{Namespace: namespace, Name: "block-storage-public"})
+
+			Eventually(func(g Gomega) {
+				cinder := GetCinder(cinderTest.Instance)
+				cinder.Spec.CinderAPI.ServiceName = "volume"
+				g.Expect(k8sClient.Update(ctx, cinder)).To(Succeed())
+			}, timeout, interval).Should(Succeed())
+
+			th.AssertServiceExists(types.NamespacedName{Namespace: namespace, Name: "volume-public"})
+			th.AssertServiceExists(types.NamespacedName{Namespace: namespace, Name: "volume-internal"})
+			th.AssertServiceDoesNotExist(types.NamespacedName{Namespace: namespace, Name: "block-storage-public"})
+			th.AssertServiceDoesNotExist(types.NamespacedName{Namespace: namespace, Name: "block-storage-internal"})
+		})
+		It("advertises the renamed Services in the API endpoints", func() {
+			Eventually(func(g Gomega) {
+				cinderAPI := GetCinderAPI(cinderTest.CinderAPI)
+				endpoints := cinderAPI.Status.APIEndpoints["cinderv3"]
+				g.Expect(endpoints["public"]).To(HavePrefix(fmt.Sprintf("http://block-storage-public.%s.svc", namespace)))
+				g.Expect(endpoints["internal"]).To(HavePrefix(fmt.Sprintf("http://block-storage-internal.%s.svc", namespace)))
+			}, timeout, interval).Should(Succeed())
+		})
+		It("names the httpd vhosts after the renamed Services", func() {
+			Eventually(func(g Gomega) {
+				configData := th.GetSecret(cinderTest.CinderConfigSecret)
+				conf := string(configData.Data["10-cinder_wsgi.conf"])
+				g.Expect(conf).Should(ContainSubstring(fmt.Sprintf("ServerName block-storage-public.%s.svc", namespace)))
+				g.Expect(conf).Should(ContainSubstring(fmt.Sprintf("ServerName block-storage-internal.%s.svc", namespace)))
+				g.Expect(conf).ShouldNot(ContainSubstring("ServerName cinder-"))
+			}, timeout, interval).Should(Succeed())
+		})
+		It("names the metrics Service after it", func() {
+			th.AssertServiceExists(types.NamespacedName{Namespace: namespace, Name: "block-storage-metrics"})
+			Eventually(func(g Gomega) {
+				g.Expect(GetCinderAPI(cinderTest.CinderAPI).Status.MetricsEndpoint).To(Equal(
+					fmt.Sprintf("http://block-storage-metrics.%s.svc:9102/metrics", namespace)))
+			}, timeout, interval).Should(Succeed())
+		})
+		It("probes the vhost of the renamed public Service", func() {
+			Eventually(func(g Gomega) {
+				ss := th.GetStatefulSet(cinderTest.CinderAPI)
+				probe := ss.Spec.Template.Spec.Containers[1].LivenessProbe
+				g.Expect(probe.HTTPGet).NotTo(BeNil())
+				g.Expect(probe.HTTPGet.HTTPHeaders).To(ContainElement(corev1.HTTPHeader{
+					Name:  "Host",
+					Value: fmt.Sprintf("block-storage-public.%s.svc", namespace),
+				}))
+			}, timeout, interval).Should(Succeed())
+		})
+	})
+	When("Cinder CR instance is built with CinderAPI extra containers", func() {
+		BeforeEach(func() {
+			apiSpec := GetDefaultCinderAPISpec()
+			apiSpec["extraContainers"] = []interface{}{
+				map[string]interface{}{
+					"name":  "audit",
+					"image": "quay.io/example/audit:latest",
+				},
+			}
+			spec := GetDefaultCinderSpec()
+			spec["cinderAPI"] = apiSpec
+			CreateCinderFixture(cinderTest, spec)
+			keystone.SimulateKeystoneServiceReady(cinderTest.CinderKeystoneService)
+		})
+		It("appends them after the managed containers", func() {
+			Eventually(func(g Gomega) {
+				ss := th.GetStatefulSet(cinderTest.CinderAPI)
+				containers := ss.Spec.Template.Spec.Containers
+				g.Expect(containers).To(HaveLen(3))
+				g.Expect(containers[0].Name).To(Equal(cinderTest.CinderAPI.Name + "-log"))
+				g.Expect(containers[1].Name).To(Equal("cinder-api"))
+				g.Expect(containers[2].Name).To(Equal("audit"))
+				g.Expect(containers[2].Image).To(Equal("quay.io/example/audit:latest"))
+			}, timeout, interval).Should(Succeed())
+		})
+	})
+	When("Cinder CR instance is built with a CinderAPI log file path", func() {
+		BeforeEach(func() {
+			apiSpec := GetDefaultCinderAPISpec()
+			apiSpec["logFilePath"] = "/var/log/cinder-api/api.log"
+			spec := GetDefaultCinderSpec()
+			spec["cinderAPI"] = apiSpec
+			CreateCinderFixture(cinderTest, spec)
+			keystone.SimulateKeystoneServiceReady(cinderTest.CinderKeystoneService)
+		})
+		It("streams and mounts the configured log file", func() {
+			Eventually(func(g Gomega) {
+				ss := th.GetStatefulSet(cinderTest.CinderAPI)
+				containers := ss.Spec.Template.Spec.Containers
+				g.Expect(containers[0].Args).To(HaveLen(5))
+				g.Expect(containers[0].Args[4]).To(Equal("/var/log/cinder-api/api.log"))
+				for _, c := range containers[:2] {
+					g.Expect(c.VolumeMounts).To(ContainElement(And(
+						HaveField("Name", "logs"),
+						HaveField("MountPath", "/var/log/cinder-api"))))
+				}
+			}, timeout, interval).Should(Succeed())
+		})
+		It("logs to the configured log file", func() {
+			Eventually(func(g Gomega) {
+				configData := th.GetSecret(types.NamespacedName{
+					Namespace: cinderTest.CinderAPI.Namespace,
+					Name:      fmt.Sprintf("%s-config-data", cinderTest.CinderAPI.Name),
+				})
+				g.Expect(string(configData.Data["01-service-defaults.conf"])).To(
+					ContainSubstring("log_file = /var/log/cinder-api/api.log"))
+			}, timeout, interval).Should(Succeed())
+		})
+	})
+	When("Cinder CR instance is built with a CinderAPI Keystone service description suffix", func() {
+		BeforeEach(func() {
+			apiSpec := GetDefaultCinderAPISpec()
+			apiSpec["keystoneServiceDescriptionSuffix"] = "Region East"
+			spec := GetDefaultCinderSpec()
+			spec["cinderAPI"] = apiSpec
+			CreateCinderFixture(cinderTest, spec)
+			keystone.SimulateKeystoneServiceReady(cinderTest.CinderKeystoneService)
+		})
+		It("appends it to the default description", func() {
+			Eventually(func(g Gomega) {
+				ksSvc := keystone.GetKeystoneService(cinderTest.CinderKeystoneService)
+				g.Expect(ksSvc.Spec.ServiceDescription).To(Equal("Cinder V3 Service - Region East"))
+			}, timeout, interval).Should(Succeed())
+		})
+	})
+	When("Cinder CR instance is built with a CinderAPI Keystone service description", func() {
+		BeforeEach(func() {
+			apiSpec := GetDefaultCinderAPISpec()
+			apiSpec["keystoneServiceDescriptions"] = map[string]interface{}{
+				"cinderv3": "Block Storage East",
+			}
+			apiSpec["keystoneServiceDescriptionSuffix"] = "Region East"
+			spec := GetDefaultCinderSpec()
+			spec["cinderAPI"] = apiSpec
+			CreateCinderFixture(cinderTest, spec)
+			keystone.SimulateKeystoneServiceReady(cinderTest.CinderKeystoneService)
+		})
+		It("registers the override as is", func() {
+			Eventually(func(g Gomega) {
+				ksSvc := keystone.GetKeystoneService(cinderTest.CinderKeystoneService)
+				g.Expect(ksSvc.Spec.ServiceDescription).To(Equal("Block Storage East"))
+			}, timeout, interval).Should(Succeed())
+		})
+	})
+	When("Cinder CR instance is built adopting an existing Keystone service", func() {
+		BeforeEach(func() {
+			ksSvc := &keystonev1.KeystoneService{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      cinderTest.CinderKeystoneService.Name,
+					Namespace: cinderTest.CinderKeystoneService.Namespace,
+				},
+				Spec: keystonev1.KeystoneServiceSpec{
+					ServiceType:        "volumev3",
+					ServiceName:        cinderTest.CinderKeystoneService.Name,
+					ServiceDescription: "Registered out-of-band",
+					Enabled:            true,
+					ServiceUser:        "cinder",
+					Secret:             SecretName,
+					PasswordSelector:   "CinderPassword",
+				},
+			}
+			Expect(k8sClient.Create(ctx, ksSvc)).To(Succeed())
+			DeferCleanup(th.DeleteInstance, ksSvc)
+			Eventually(func(g Gomega) {
+				ksSvc := keystone.GetKeystoneService(cinderTest.CinderKeystoneService)
+				ksSvc.Status.ServiceID = "adopted-service-id"
+				g.Expect(k8sClient.Status().Update(ctx, ksSvc)).To(Succeed())
+			}, timeout, interval).Should(Succeed())
+
+			apiSpec := GetDefaultCinderAPISpec()
+			apiSpec["adoptExistingKeystoneService"] = true
+			spec := GetDefaultCinderSpec()
+			spec["cinderAPI"] = apiSpec
+			CreateCinderFixture(cinderTest, spec)
+		})
+		It("takes the service ID without managing the service", func() {
+			Eventually(func(g Gomega) {
+				cinderAPI := GetCinderAPI(cinderTest.CinderAPI)
+				g.Expect(cinderAPI.Status.ServiceIDs).To(HaveKeyWithValue(
+					cinderTest.CinderKeystoneService.Name, "adopted-service-id"))
+			}, timeout, interval).Should(Succeed())
+			th.ExpectCondition(
+				cinderTest.CinderAPI,
+				ConditionGetterFunc(CinderAPIConditionGetter),
+				condition.KeystoneServiceReadyCondition,
+				corev1.ConditionTrue,
+			)
+
+			ksSvc := keystone.GetKeystoneService(cinderTest.CinderKeystoneService)
+			Expect(ksSvc.Spec.ServiceDescription).To(Equal("Registered out-of-band"))
+			Expect(ksSvc.OwnerReferences).To(BeEmpty())
+			Consistently(func(g Gomega) {
+				ksEndpt := &keystonev1.KeystoneEndpoint{}
+				err := k8sClient.Get(ctx, cinderTest.CinderKeystoneEndpoint, ksEndpt)
+				g.Expect(k8s_errors.IsNotFound(err)).To(BeTrue())
+			}, timeout, interval).Should(Succeed())
+		})
+		It("leaves the adopted service when deleted", func() {
+			Eventually(func(g Gomega) {
+				cinderAPI := GetCinderAPI(cinderTest.CinderAPI)
+				g.Expect(cinderAPI.Status.ServiceIDs).To(HaveKey(cinderTest.CinderKeystoneService.Name))
+			}, timeout, interval).Should(Succeed())
+
+			th.DeleteInstance(GetCinder(cinderTest.Instance))
+			th.DeleteInstance(GetCinderAPI(cinderTest.CinderAPI))
+
+			Consistently(func(g Gomega) {
+				ksSvc := keystone.GetKeystoneService(cinderTest.CinderKeystoneService)
+				g.Expect(ksSvc.DeletionTimestamp).To(BeNil())
+				g.Expect(ksSvc.Status.ServiceID).To(Equal("adopted-service-id"))
+			}, timeout, interval).Should(Succeed())
+		})
+	})
+	When("Cinder CR instance is built with a requeue interval", func() {
+		BeforeEach(func() {
+			spec := GetDefaultCinderSpec()
+			spec["requeueInterval"] = "30s"
+			CreateCinderFixture(cinderTest, spec)
+			keystone.SimulateKeystoneServiceReady(cinderTest.CinderKeystoneService)
+		})
+		It("passes it to the CinderAPI", func() {
+			Eventually(func(g Gomega) {
+				cinderAPI := GetCinderAPI(cinderTest.CinderAPI)
+				g.Expect(cinderAPI.Spec.RequeueInterval).NotTo(BeNil())
+				g.Expect(cinderAPI.Spec.RequeueInterval.Duration).To(Equal(30 * time.Second))
+			}, timeout, interval).Should(Succeed())
+		})
+		It("requeues at the interval of the CR", func() {
+			cinderAPI := GetCinderAPI(cinderTest.CinderAPI)
+			Expect(cinder.GetRequeueInterval(cinderAPI.Spec.CinderTemplate, time.Minute)).To(Equal(30 * time.Second))
+		})
+	})
+	When("the requeue interval is not set on the CR", func() {
+		It("requeues at the interval of the controller", func() {
+			tmpl := cinderv1.CinderTemplate{}
+			Expect(cinder.GetRequeueInterval(tmpl, time.Minute)).To(Equal(time.Minute))
+		})
+		It("requeues at the default interval without a controller interval", func() {
+			tmpl := cinderv1.CinderTemplate{}
+			Expect(cinder.GetRequeueInterval(tmpl, 0)).To(Equal(cinder.DefaultRequeueInterval))
+		})
+		It("ignores a zero interval of the CR", func() {
+			tmpl := cinderv1.CinderTemplate{RequeueInterval: &metav1.Duration{}}
+			Expect(cinder.GetRequeueInterval(tmpl, time.Minute)).To(Equal(time.Minute))
+		})
+	})
+	When("Cinder CR instance is built with a CinderAPI dry run", func() {
+		BeforeEach(func() {
+			apiSpec := GetDefaultCinderAPISpec()
+			apiSpec["dryRun"] = true
+			spec := GetDefaultCinderSpec()
+			spec["cinderAPI"] = apiSpec
+			CreateCinderFixture(cinderTest, spec)
+		})
+		It("reports the planned changes", func() {
+			Eventually(func(g Gomega) {
+				conditions := CinderAPIConditionGetter(cinderTest.CinderAPI)
+				g.Expect(conditions.IsTrue(cinderv1.DryRunCondition)).To(BeTrue())
+				message := conditions.Get(cinderv1.DryRunCondition).Message
+				g.Expect(message).To(ContainSubstring(fmt.Sprintf("StatefulSet %s with 1 replicas", cinderTest.CinderAPI.Name)))
+				g.Expect(message).To(ContainSubstring("Service cinder-public on port 8776"))
+				g.Expect(message).To(ContainSubstring("Service cinder-internal on port 8776"))
+				g.Expect(message).To(ContainSubstring("KeystoneService cinderv3 (Cinder V3 Service)"))
+			}, timeout, interval).Should(Succeed())
+		})
+		It("creates none of the cinder-api resources", func() {
+			Consistently(func(g Gomega) {
+				err := k8sClient.Get(ctx, cinderTest.CinderAPI, &appsv1.StatefulSet{})
+				g.Expect(k8s_errors.IsNotFound(err)).To(BeTrue())
+				err = k8sClient.Get(ctx, cinderTest.CinderServicePublic, &corev1.Service{})
+				g.Expect(k8s_errors.IsNotFound(err)).To(BeTrue())
+				err = k8sClient.Get(ctx, cinderTest.CinderKeystoneService, &keystonev1.KeystoneService{})
+				g.Expect(k8s_errors.IsNotFound(err)).To(BeTrue())
+			}, timeout, interval).Should(Succeed())
+		})
+	})
+	When("Cinder CR instance is built with a CinderAPI which gets paused", func() {
+		BeforeEach(func() {
+			apiSpec := GetDefaultCinderAPISpec()
+			spec := GetDefaultCinderSpec()
+			spec["cinderAPI"] = apiSpec
+			CreateCinderFixture(cinderTest, spec)
+			keystone.SimulateKeystoneServiceReady(cinderTest.CinderKeystoneService)
+		})
+		It("leaves the StatefulSet alone until resumed", func() {
+			Eventually(func(g Gomega) {
+				g.Expect(*th.GetStatefulSet(cinderTest.CinderAPI).Spec.Replicas).To(Equal(int32(1)))
+			}, timeout, interval).Should(Succeed())
+
+			Eventually(func(g Gomega) {
+				cinder := GetCinder(cinderTest.Instance)
+				cinder.Spec.CinderAPI.Paused = true
+				g.Expect(k8sClient.Update(ctx, cinder)).To(Succeed())
+			}, timeout, interval).Should(Succeed())
+			th.ExpectCondition(
+				cinderTest.CinderAPI,
+				ConditionGetterFunc(CinderAPIConditionGetter),
+				cinderv1.ReconciliationPausedCondition,
+				corev1.ConditionTrue,
+			)
+
+			Eventually(func(g Gomega) {
+				cinder := GetCinder(cinderTest.Instance)
+				cinder.Spec.CinderAPI.Replicas = ptr.To(int32(2))
+				g.Expect(k8sClient.Update(ctx, cinder)).To(Succeed())
+			}, timeout, interval).Should(Succeed())
+			Eventually(func(g Gomega) {
+				g.Expect(*GetCinderAPI(cinderTest.CinderAPI).Spec.Replicas).To(Equal(int32(2)))
+			}, timeout, interval).Should(Succeed())
+			Consistently(func(g Gomega) {
+				g.Expect(*th.GetStatefulSet(cinderTest.CinderAPI).Spec.Replicas).To(Equal(int32(1)))
+			}, timeout, interval).Should(Succeed())
+
+			Eventually(func(g Gomega) {
+				cinder := GetCinder(cinderTest.Instance)
+				cinder.Spec.CinderAPI.Paused = false
+				g.Expect(k8sClient.Update(ctx, cinder)).To(Succeed())
+			}, timeout, interval).Should(Succeed())
+			Eventually(func(g Gomega) {
+				g.Expect(*th.GetStatefulSet(cinderTest.CinderAPI).Spec.Replicas).To(Equal(int32(2)))
+				conditions := CinderAPIConditionGetter(cinderTest.CinderAPI)
+				g.Expect(conditions.Has(cinderv1.ReconciliationPausedCondition)).To(BeFalse())
+			}, timeout, interval).Should(Succeed())
+		})
+	})
+	When("Cinder CR instance is built with CinderAPI extra env", func() {
+		BeforeEach(func() {
+			apiSpec := GetDefaultCinderAPISpec()
+			apiSpec["extraEnv"] = map[string]interface{}{
+				"HTTPS_PROXY": "http://proxy.example.com:3128",
+				"CONFIG_HASH": "overridden",
+			}
+			spec := GetDefaultCinderSpec()
+			spec["cinderAPI"] = apiSpec
+			CreateCinderFixture(cinderTest, spec)
+			keystone.SimulateKeystoneServiceReady(cinderTest.CinderKeystoneService)
+		})
+		It("adds the extra env to the API container only", func() {
+			Eventually(func(g Gomega) {
+				ss := th.GetStatefulSet(cinderTest.CinderAPI)
+				container := ss.Spec.Template.Spec.Containers[1]
+				g.Expect(GetEnvVarValue(container.Env, "HTTPS_PROXY", "")).To(Equal("http://proxy.example.com:3128"))
+				logContainer := ss.Spec.Template.Spec.Containers[0]
+				g.Expect(GetEnvVarValue(logContainer.Env, "HTTPS_PROXY", "")).To(BeEmpty())
+			}, timeout, interval).Should(Succeed())
+		})
+		It("doesn't let the extra env override the operator env", func() {
+			Eventually(func(g Gomega) {
+				ss := th.GetStatefulSet(cinderTest.CinderAPI)
+				container := ss.Spec.Template.Spec.Containers[1]
+				configHash := GetEnvVarValue(container.Env, "CONFIG_HASH", "")
+				g.Expect(configHash).ToNot(Equal("overridden"))
+				g.Expect(configHash).To(Equal(GetEnvVarValue(ss.Spec.Template.Spec.Containers[0].Env, "CONFIG_HASH", "")))
+
+				names := []string{}
+				for _, envVar := range container.Env {
+					names = append(names, envVar.Name)
+				}
+				// the extra env is set ahead of the operator env
+				g.Expect(names).To(Equal([]string{"CONFIG_HASH", "HTTPS_PROXY", "KOLLA_CONFIG_FILE", "KOLLA_CONFIG_STRATEGY"}))
+			}, timeout, interval).Should(Succeed())
+		})
+	})
+	When("Cinder CR instance is built with a CinderAPI HealthCheckPath", func() {
+		It("rejects a path not starting with /", func() {
+			apiSpec := GetDefaultCinderAPISpec()
+			apiSpec["healthCheckPath"] = "healthcheck"
+			spec := GetDefaultCinderSpec()
+			spec["cinderAPI"] = apiSpec
+			raw := map[string]interface{}{
+				"apiVersion": "cinder.openstack.org/v1beta1",
+				"kind":       "Cinder",
+				"metadata": map[string]interface{}{
+					"name":      cinderTest.Instance.Name,
+					"namespace": cinderTest.Instance.Namespace,
+				},
+				"spec": spec,
+			}
+			err := k8sClient.Create(ctx, &unstructured.Unstructured{Object: raw})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("healthCheckPath"))
+		})
+	})
+	When("Cinder CR instance is built with ServiceAccount pull secrets", func() {
+		BeforeEach(func() {
+			spec := GetDefaultCinderSpec()
+			spec["serviceAccountImagePullSecrets"] = []map[string]interface{}{
+				{"name": "registry-pull-secret"},
+			}
+			DeferCleanup(th.DeleteInstance, CreateCinder(cinderTest.Instance, spec))
+			DeferCleanup(k8sClient.Delete, ctx, CreateCinderMessageBusSecret(cinderTest.Instance.Namespace, cinderTest.RabbitmqSecretName))
+		})
+		It("attaches the pull secrets to the service account", func() {
+			th.ExpectCondition(
+				cinderName,
+				ConditionGetterFunc(CinderConditionGetter),
+				condition.ServiceAccountReadyCondition,
+				corev1.ConditionTrue,
+			)
+			Eventually(func(g Gomega) {
+				sa := th.GetServiceAccount(cinderTest.CinderSA)
+				g.Expect(sa.ImagePullSecrets).To(ContainElement(
+					corev1.LocalObjectReference{Name: "registry-pull-secret"}))
+			}, timeout, interval).Should(Succeed())
+		})
+		It("detaches the pull secrets removed from the spec", func() {
+			Eventually(func(g Gomega) {
+				sa := th.GetServiceAccount(cinderTest.CinderSA)
+				g.Expect(sa.ImagePullSecrets).To(ContainElement(
+					corev1.LocalObjectReference{Name: "registry-pull-secret"}))
+			}, timeout, interval).Should(Succeed())
+
+			Eventually(func(g Gomega) {
+				cinder := GetCinder(cinderName)
+				cinder.Spec.ServiceAccountImagePullSecrets = nil
+				g.Expect(k8sClient.Update(ctx, cinder)).To(Succeed())
+			}, timeout, interval).Should(Succeed())
+
+			Eventually(func(g Gomega) {
+				sa := th.GetServiceAccount(cinderTest.CinderSA)
+				g.Expect(sa.ImagePullSecrets).NotTo(ContainElement(
+					corev1.LocalObjectReference{Name: "registry-pull-secret"}))
+				g.Expect(sa.Annotations).NotTo(HaveKey(cinderv1.ImagePullSecretsAnnotation))
+			}, timeout, interval).Should(Succeed())
+		})
+	})
+	When("A Cinder with TLS is created", func() {
+		BeforeEach(func() {
+			DeferCleanup(th.DeleteInstance, CreateCinder(cinderTest.Instance, GetTLSCinderSpec()))
+			DeferCleanup(k8sClient.Delete, ctx, CreateCinderMessageBusSecret(cinderTest.Instance.Namespace, cinderTest.RabbitmqSecretName))
+			DeferCleanup(th.DeleteInstance, CreateCinderAPI(cinderTest.Instance, GetDefaultCinderAPISpec()))
+			DeferCleanup(th.DeleteInstance, CreateCinderScheduler(cinderTest.Instance, GetDefaultCinderSchedulerSpec()))
+			DeferCleanup(th.DeleteInstance, CreateCinderVolume(cinderTest.Instance, GetDefaultCinderVolumeSpec()))
+			DeferCleanup(
+				mariadb.DeleteDBService,
+				mariadb.CreateDBService(
+					cinderTest.Instance.Namespace,
+					GetCinder(cinderName).Spec.DatabaseInstance,
+					corev1.ServiceSpec{
+						Ports: []corev1.ServicePort{{Port: 3306}},
+					},
+				),
+			)
+			infra.SimulateTransportURLReady(cinderTest.CinderTransportURL)
+			DeferCleanup(infra.DeleteMemcached, infra.CreateMemcached(namespace, cinderTest.MemcachedInstance, memcachedSpec))
+			infra.SimulateMemcachedReady(cinderTest.CinderMemcached)
+			DeferCleanup(keystone.DeleteKeystoneAPI, keystone.CreateKeystoneAPI(cinderTest.Instance.Namespace))
+			mariadb.SimulateMariaDBAccountCompleted(cinderTest.Instance)
+			mariadb.SimulateMariaDBDatabaseCompleted(cinderTest.Instance)
+			th.SimulateJobSuccess(cinderTest.CinderDBSync)
+		})
+
+		It("reports that the CA secret is missing", func() {
+			th.ExpectConditionWithDetails(
+				cinderTest.CinderAPI,
+				ConditionGetterFunc(CinderAPIConditionGetter),
+				condition.TLSInputReadyCondition,
+				corev1.ConditionFalse,
+				condition.ErrorReason,
+				fmt.Sprintf("TLSInput error occured in TLS sources Secret %s/combined-ca-bundle not found", namespace),
+			)
+
+			th.ExpectConditionWithDetails(
+				cinderTest.CinderScheduler,
+				ConditionGetterFunc(CinderSchedulerConditionGetter),
+				condition.TLSInputReadyCondition,
+				corev1.ConditionFalse,
+				condition.ErrorReason,
+				fmt.Sprintf("TLSInput error occured in TLS sources Secret %s/combined-ca-bundle not found", namespace),
+			)
+		})
+
+		It("reports that the internal cert secret is missing", func() {
+			DeferCleanup(k8sClient.Delete, ctx, th.CreateCABundleSecret(cinderTest.CABundleSecret))
+			th.ExpectConditionWithDetails(
+				cinderTest.CinderAPI,
+				ConditionGetterFunc(CinderAPIConditionGetter),
+				condition.TLSInputReadyCondition,
+				corev1.ConditionFalse,
+				condition.ErrorReason,
 				fmt.Sprintf("TLSInput error occured in TLS sources Secret %s/internal-tls-certs not found", namespace),
 			)
 		})
@@ -540,6 +2318,24 @@ var _ = Describe("Cinder controller", func() {
 
 			Expect(container.ReadinessProbe.HTTPGet.Scheme).To(Equal(corev1.URISchemeHTTPS))
 			Expect(container.LivenessProbe.HTTPGet.Scheme).To(Equal(corev1.URISchemeHTTPS))
+			// by default the probes target the public endpoint vhost
+			Expect(container.LivenessProbe.HTTPGet.HTTPHeaders).To(ContainElement(
+				corev1.HTTPHeader{Name: "Host", Value: "cinder-public." + namespace + ".svc"}))
+		})
+		It("stores https API endpoints and registers them in keystone", func() {
+			DeferCleanup(k8sClient.Delete, ctx, th.CreateCABundleSecret(cinderTest.CABundleSecret))
+			DeferCleanup(k8sClient.Delete, ctx, th.CreateCertSecret(cinderTest.InternalCertSecret))
+			DeferCleanup(k8sClient.Delete, ctx, th.CreateCertSecret(cinderTest.PublicCertSecret))
+			keystone.SimulateKeystoneServiceReady(cinderTest.CinderKeystoneService)
+
+			Eventually(func(g Gomega) {
+				endpoints := GetCinderAPI(cinderTest.CinderAPI).Status.APIEndpoints["cinderv3"]
+				g.Expect(endpoints).To(HaveKeyWithValue("public", "https://cinder-public."+namespace+".svc:8776/v3"))
+				g.Expect(endpoints).To(HaveKeyWithValue("internal", "https://cinder-internal."+namespace+".svc:8776/v3"))
+
+				ksEndpoint := keystone.GetKeystoneEndpoint(cinderTest.CinderKeystoneEndpoint)
+				g.Expect(ksEndpoint.Spec.Endpoints).To(Equal(endpoints))
+			}, timeout, interval).Should(Succeed())
 		})
 		It("Creates CinderScheduler", func() {
 			DeferCleanup(k8sClient.Delete, ctx, th.CreateCABundleSecret(cinderTest.CABundleSecret))
@@ -590,6 +2386,30 @@ var _ = Describe("Cinder controller", func() {
 			Expect(endpoints).To(HaveKeyWithValue("internal", "https://cinder-internal."+namespace+".svc:8776/v3"))
 		})
 
+		It("restarts the CinderAPI pods when the public cert rotates", func() {
+			DeferCleanup(k8sClient.Delete, ctx, th.CreateCABundleSecret(cinderTest.CABundleSecret))
+			DeferCleanup(k8sClient.Delete, ctx, th.CreateCertSecret(cinderTest.InternalCertSecret))
+			DeferCleanup(k8sClient.Delete, ctx, th.CreateCertSecret(cinderTest.PublicCertSecret))
+			keystone.SimulateKeystoneServiceReady(cinderTest.CinderKeystoneService)
+			keystone.SimulateKeystoneEndpointReady(cinderTest.CinderKeystoneEndpoint)
+
+			var configHash string
+			Eventually(func(g Gomega) {
+				configHash = GetEnvVarValue(
+					th.GetStatefulSet(cinderTest.CinderAPI).Spec.Template.Spec.Containers[0].Env, "CONFIG_HASH", "")
+				g.Expect(configHash).NotTo(BeEmpty())
+			}, timeout, interval).Should(Succeed())
+
+			th.UpdateSecret(cinderTest.PublicCertSecret, "tls.crt", []byte("RotatedCertData"))
+
+			Eventually(func(g Gomega) {
+				newHash := GetEnvVarValue(
+					th.GetStatefulSet(cinderTest.CinderAPI).Spec.Template.Spec.Containers[0].Env, "CONFIG_HASH", "")
+				g.Expect(newHash).NotTo(BeEmpty())
+				g.Expect(newHash).NotTo(Equal(configHash))
+			}, timeout, interval).Should(Succeed())
+		})
+
 		It("reconfigures the cinder pods when CA changes", func() {
 			DeferCleanup(k8sClient.Delete, ctx, th.CreateCABundleSecret(cinderTest.CABundleSecret))
 			DeferCleanup(k8sClient.Delete, ctx, th.CreateCertSecret(cinderTest.InternalCertSecret))
@@ -627,4 +2447,318 @@ var _ = Describe("Cinder controller", func() {
 			}, timeout, interval).Should(Succeed())
 		})
 	})
+	When("Cinder CR has a Bidirectional ExtraMount propagated to every pod", func() {
+		It("is rejected by the webhook", func() {
+			spec := GetDefaultCinderSpec()
+			spec["extraMounts"] = []interface{}{
+				map[string]interface{}{
+					"extraVol": []interface{}{
+						map[string]interface{}{
+							"mounts": []interface{}{
+								map[string]interface{}{
+									"name":             "shared",
+									"mountPath":        "/var/lib/shared",
+									"mountPropagation": "Bidirectional",
+								},
+							},
+							"volumes": []interface{}{
+								map[string]interface{}{
+									"name":     "shared",
+									"hostPath": map[string]interface{}{"path": "/var/lib/shared"},
+								},
+							},
+						},
+					},
+				},
+			}
+			raw := map[string]interface{}{
+				"apiVersion": "cinder.openstack.org/v1beta1",
+				"kind":       "Cinder",
+				"metadata": map[string]interface{}{
+					"name":      cinderTest.Instance.Name,
+					"namespace": cinderTest.Instance.Namespace,
+				},
+				"spec": spec,
+			}
+			err := k8sClient.Create(ctx, &unstructured.Unstructured{Object: raw})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("mountPropagation"))
+		})
+	})
+	When("the pods get a default node selector", func() {
+		It("merges it with the node selector of the spec, whose keys win", func() {
+			Expect(cinder.GetNodeSelector(
+				map[string]string{"node-role": "cinder", "zone": "east"},
+				map[string]string{"node-role": "storage", "disk": "ssd"},
+			)).To(Equal(map[string]string{"node-role": "cinder", "zone": "east", "disk": "ssd"}))
+		})
+		It("uses the default when the spec has no node selector", func() {
+			Expect(cinder.GetNodeSelector(nil, map[string]string{"node-role": "storage"})).To(
+				Equal(map[string]string{"node-role": "storage"}))
+		})
+		It("uses the spec when there is no default", func() {
+			Expect(cinder.GetNodeSelector(map[string]string{"node-role": "cinder"}, nil)).To(
+				Equal(map[string]string{"node-role": "cinder"}))
+		})
+		It("sets no node selector when both are empty", func() {
+			Expect(cinder.GetNodeSelector(map[string]string{}, nil)).To(BeNil())
+		})
+		It("parses the default of the operator flag", func() {
+			Expect(cinder.ParseNodeSelector("node-role=storage, disk=ssd")).To(
+				Equal(map[string]string{"node-role": "storage", "disk": "ssd"}))
+			Expect(cinder.ParseNodeSelector("")).To(BeEmpty())
+		})
+		It("rejects an invalid default of the operator flag", func() {
+			_, err := cinder.ParseNodeSelector("node-role")
+			Expect(err).To(HaveOccurred())
+			_, err = cinder.ParseNodeSelector("node role=storage")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+	When("Cinder CR has an invalid CinderAPI node selector", func() {
+		It("is rejected by the webhook", func() {
+			apiSpec := GetDefaultCinderAPISpec()
+			apiSpec["nodeSelector"] = map[string]interface{}{
+				"node role": "storage",
+			}
+			spec := GetDefaultCinderSpec()
+			spec["cinderAPI"] = apiSpec
+			raw := map[string]interface{}{
+				"apiVersion": "cinder.openstack.org/v1beta1",
+				"kind":       "Cinder",
+				"metadata": map[string]interface{}{
+					"name":      cinderTest.Instance.Name,
+					"namespace": cinderTest.Instance.Namespace,
+				},
+				"spec": spec,
+			}
+			err := k8sClient.Create(ctx, &unstructured.Unstructured{Object: raw})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("spec.cinderAPI.nodeSelector"))
+		})
+	})
+	When("CinderAPI CR has an extra container named like a managed one", func() {
+		It("is rejected by the webhook", func() {
+			spec := GetDefaultCinderAPISpec()
+			spec["extraContainers"] = []interface{}{
+				map[string]interface{}{
+					"name":  "cinder-api",
+					"image": "quay.io/example/audit:latest",
+				},
+			}
+			raw := map[string]interface{}{
+				"apiVersion": "cinder.openstack.org/v1beta1",
+				"kind":       "CinderAPI",
+				"metadata": map[string]interface{}{
+					"name":      cinderTest.CinderAPI.Name,
+					"namespace": cinderTest.CinderAPI.Namespace,
+				},
+				"spec": spec,
+			}
+			err := k8sClient.Create(ctx, &unstructured.Unstructured{Object: raw})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("spec.extraContainers[0].name"))
+		})
+	})
+	When("Cinder CR has an extra CinderAPI container named like the log container", func() {
+		It("is rejected by the webhook", func() {
+			apiSpec := GetDefaultCinderAPISpec()
+			apiSpec["extraContainers"] = []interface{}{
+				map[string]interface{}{
+					"name":  cinderTest.Instance.Name + "-api-log",
+					"image": "quay.io/example/audit:latest",
+				},
+			}
+			spec := GetDefaultCinderSpec()
+			spec["cinderAPI"] = apiSpec
+			raw := map[string]interface{}{
+				"apiVersion": "cinder.openstack.org/v1beta1",
+				"kind":       "Cinder",
+				"metadata": map[string]interface{}{
+					"name":      cinderTest.Instance.Name,
+					"namespace": cinderTest.Instance.Namespace,
+				},
+				"spec": spec,
+			}
+			err := k8sClient.Create(ctx, &unstructured.Unstructured{Object: raw})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("spec.cinderAPI.extraContainers[0].name"))
+		})
+	})
+	When("CinderAPI CR has a TLS endpoint with an empty secretName", func() {
+		It("is rejected by the webhook", func() {
+			spec := GetDefaultCinderAPISpec()
+			spec["tls"] = map[string]interface{}{
+				"api": map[string]interface{}{
+					"public": map[string]interface{}{
+						"secretName": "",
+					},
+				},
+			}
+			raw := map[string]interface{}{
+				"apiVersion": "cinder.openstack.org/v1beta1",
+				"kind":       "CinderAPI",
+				"metadata": map[string]interface{}{
+					"name":      cinderTest.CinderAPI.Name,
+					"namespace": cinderTest.CinderAPI.Namespace,
+				},
+				"spec": spec,
+			}
+			err := k8sClient.Create(ctx, &unstructured.Unstructured{Object: raw})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("spec.tls.api.public.secretName"))
+		})
+	})
+	When("CinderAPI CR is created without image, replicas and service account", func() {
+		BeforeEach(func() {
+			spec := GetDefaultCinderAPISpec()
+			delete(spec, "containerImage")
+			delete(spec, "replicas")
+			delete(spec, "serviceAccount")
+			DeferCleanup(th.DeleteInstance, CreateCinderAPI(cinderTest.CinderAPI, spec))
+		})
+		It("gets them defaulted by the webhook", func() {
+			cinderAPI := GetCinderAPI(cinderTest.CinderAPI)
+			Expect(cinderAPI.Spec.ContainerImage).To(Equal(util.GetEnvVar("RELATED_IMAGE_CINDER_API_IMAGE_URL_DEFAULT", cinderv1.CinderAPIContainerImage)))
+			Expect(*cinderAPI.Spec.Replicas).To(Equal(int32(1)))
+			Expect(cinderAPI.Spec.ServiceAccount).To(Equal("cinder-cinder"))
+		})
+	})
+	When("CinderAPI CR is created with image, replicas and service account", func() {
+		BeforeEach(func() {
+			spec := GetDefaultCinderAPISpec()
+			spec["replicas"] = 0
+			DeferCleanup(th.DeleteInstance, CreateCinderAPI(cinderTest.CinderAPI, spec))
+		})
+		It("keeps them", func() {
+			cinderAPI := GetCinderAPI(cinderTest.CinderAPI)
+			Expect(cinderAPI.Spec.ContainerImage).To(Equal(cinderTest.ContainerImage))
+			Expect(*cinderAPI.Spec.Replicas).To(Equal(int32(0)))
+			Expect(cinderAPI.Spec.ServiceAccount).To(Equal(cinderTest.CinderSA.Name))
+		})
+	})
+	When("CinderAPI CR is created before the Secrets of its parent Cinder", func() {
+		BeforeEach(func() {
+			DeferCleanup(k8sClient.Delete, ctx, CreateCinderMessageBusSecret(cinderTest.Instance.Namespace, cinderTest.RabbitmqSecretName))
+			DeferCleanup(keystone.DeleteKeystoneAPI, keystone.CreateKeystoneAPI(cinderTest.Instance.Namespace))
+			raw := map[string]interface{}{
+				"apiVersion": "cinder.openstack.org/v1beta1",
+				"kind":       "CinderAPI",
+				"metadata": map[string]interface{}{
+					"name":      cinderTest.CinderAPI.Name,
+					"namespace": cinderTest.CinderAPI.Namespace,
+					"ownerReferences": []interface{}{
+						map[string]interface{}{
+							"apiVersion": "cinder.openstack.org/v1beta1",
+							"kind":       "Cinder",
+							"name":       cinderTest.Instance.Name,
+							"uid":        "3f0a4b34-8a4d-4c1e-9d6c-8b7d0f4a1c2e",
+						},
+					},
+				},
+				"spec": GetDefaultCinderAPISpec(),
+			}
+			DeferCleanup(th.DeleteInstance, CreateUnstructured(raw))
+		})
+		It("reports which parent Secret is missing", func() {
+			th.ExpectConditionWithDetails(
+				cinderTest.CinderAPI,
+				ConditionGetterFunc(CinderAPIConditionGetter),
+				condition.InputReadyCondition,
+				corev1.ConditionFalse,
+				cinderv1.ParentInputMissingReason,
+				fmt.Sprintf("Input data not ready: Secret %s-scripts of the parent Cinder %s not found",
+					cinderTest.Instance.Name, cinderTest.Instance.Name),
+			)
+		})
+	})
+	When("CinderAPI CR is created without its OpenStack secret", func() {
+		BeforeEach(func() {
+			DeferCleanup(keystone.DeleteKeystoneAPI, keystone.CreateKeystoneAPI(cinderTest.Instance.Namespace))
+			spec := GetDefaultCinderAPISpec()
+			spec["secret"] = "missing-osp-secret"
+			DeferCleanup(th.DeleteInstance, CreateCinderAPI(cinderTest.CinderAPI, spec))
+		})
+		It("reports the missing Secret", func() {
+			th.ExpectConditionWithDetails(
+				cinderTest.CinderAPI,
+				ConditionGetterFunc(CinderAPIConditionGetter),
+				condition.InputReadyCondition,
+				corev1.ConditionFalse,
+				cinderv1.SecretMissingReason,
+				"Input data not ready: Secret missing-osp-secret not found",
+			)
+		})
+	})
+	When("CinderAPI CR selects a password key missing from its OpenStack secret", func() {
+		BeforeEach(func() {
+			DeferCleanup(keystone.DeleteKeystoneAPI, keystone.CreateKeystoneAPI(cinderTest.Instance.Namespace))
+			spec := GetDefaultCinderAPISpec()
+			spec["passwordSelectors"] = map[string]interface{}{
+				"service": "CinderPasswrod",
+			}
+			DeferCleanup(th.DeleteInstance, CreateCinderAPI(cinderTest.CinderAPI, spec))
+		})
+		It("reports the missing key", func() {
+			th.ExpectConditionWithDetails(
+				cinderTest.CinderAPI,
+				ConditionGetterFunc(CinderAPIConditionGetter),
+				condition.InputReadyCondition,
+				corev1.ConditionFalse,
+				cinderv1.SecretKeyMissingReason,
+				fmt.Sprintf("Input data not ready: key CinderPasswrod not found in Secret %s", SecretName),
+			)
+		})
+		It("does not register the Keystone service", func() {
+			Consistently(func(g Gomega) {
+				ksSvc := &keystonev1.KeystoneService{}
+				err := k8sClient.Get(ctx, cinderTest.CinderKeystoneService, ksSvc)
+				g.Expect(k8s_errors.IsNotFound(err)).To(BeTrue())
+			}, timeout, interval).Should(Succeed())
+		})
+	})
+	When("CinderAPI CR references a CA bundle secret which doesn't exist", func() {
+		It("is admitted with a warning", func() {
+			instance := &cinderv1.CinderAPI{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      cinderTest.CinderAPI.Name,
+					Namespace: cinderTest.CinderAPI.Namespace,
+				},
+			}
+			instance.Spec.TLS.CaBundleSecretName = "missing-ca-bundle"
+			warnings, err := instance.ValidateCreate()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(warnings).To(HaveLen(1))
+			Expect(warnings[0]).To(ContainSubstring("missing-ca-bundle"))
+
+			DeferCleanup(k8sClient.Delete, ctx, th.CreateSecret(
+				types.NamespacedName{Name: "missing-ca-bundle", Namespace: cinderTest.CinderAPI.Namespace},
+				map[string][]byte{"tls-ca-bundle.pem": []byte("CAData")},
+			))
+			warnings, err = instance.ValidateCreate()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(warnings).To(BeEmpty())
+		})
+	})
+	When("CinderAPI CR enables TLS with a cert secret which doesn't exist", func() {
+		It("is admitted with a warning", func() {
+			instance := &cinderv1.CinderAPI{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      cinderTest.CinderAPI.Name,
+					Namespace: cinderTest.CinderAPI.Namespace,
+				},
+			}
+			instance.Spec.TLS.API.Public.SecretName = ptr.To(PublicCertSecretName)
+			warnings, err := instance.ValidateCreate()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(warnings).To(HaveLen(1))
+			Expect(warnings[0]).To(ContainSubstring("spec.tls.api.public.secretName"))
+			Expect(warnings[0]).To(ContainSubstring(PublicCertSecretName))
+
+			DeferCleanup(k8sClient.Delete, ctx, th.CreateCertSecret(cinderTest.PublicCertSecret))
+			warnings, err = instance.ValidateCreate()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(warnings).To(BeEmpty())
+		})
+	})
 })